@@ -0,0 +1,315 @@
+package apu
+
+import "github.com/laranc/emuNES/timing"
+
+const (
+	DefaultSampleRate = 44100
+	maxPendingSamples = 16384
+)
+
+// CPU cycles of each frame counter step: three quarter frames, the 4-step
+// IRQ window and end, then the last 5-step clock and end
+var frameStepsNTSC = [8]int{7457, 14913, 22371, 29828, 29829, 29830, 37281, 37282}
+var frameStepsPAL = [8]int{8313, 16627, 24939, 33252, 33253, 33254, 41565, 41566}
+
+type Bus interface {
+	Read(addr uint16, readOnly bool) uint8
+}
+
+type APU struct {
+	pulse1       pulse
+	pulse2       pulse
+	triangle     triangle
+	noise        noise
+	dmc          dmc
+	frameMode    uint8 // 0: 4-step, 1: 5-step
+	frameInhibit bool
+	frameIRQ     bool
+	frameCounter int
+	frameReset   int
+	frameSteps   [8]int
+	cpuFrequency float64
+	cycle        uint64
+	sampleRate   float64
+	sampleClock  float64
+	filters      [3]filter
+	samples      []float32
+	pulseTable   [31]float32
+	tndTable     [203]float32
+	expansion    func() float32 // Cartridge sound, nil without one
+	bus          Bus
+}
+
+func NewAPU() *APU {
+	apu := &APU{
+		pulse1:       makePulse(1),
+		pulse2:       makePulse(2),
+		triangle:     triangle{},
+		noise:        makeNoise(),
+		dmc:          makeDMC(),
+		frameMode:    0,
+		frameInhibit: false,
+		frameIRQ:     false,
+		frameCounter: 0,
+		frameReset:   0,
+		frameSteps:   frameStepsNTSC,
+		cpuFrequency: timing.NTSC.CPUFrequency(),
+		cycle:        0,
+		sampleRate:   0,
+		sampleClock:  0,
+		filters:      [3]filter{},
+		samples:      make([]float32, 0, 4096),
+		bus:          nil,
+	}
+	for i := 1; i < len(apu.pulseTable); i++ {
+		apu.pulseTable[i] = float32(95.52 / (8128.0/float64(i) + 100))
+	}
+	for i := 1; i < len(apu.tndTable); i++ {
+		apu.tndTable[i] = float32(163.67 / (24329.0/float64(i) + 100))
+	}
+	apu.SetSampleRate(DefaultSampleRate)
+	return apu
+}
+
+func (apu *APU) ConnectBus(bus Bus) {
+	apu.bus = bus
+}
+
+// Safe to call every frame, filter state is kept so rate control does not click
+func (apu *APU) SetSampleRate(rate float64) {
+	apu.sampleRate = rate
+	filters := [3]filter{
+		makeHighPass(rate, 90),
+		makeHighPass(rate, 440),
+		makeLowPass(rate, 14000),
+	}
+	for i := range filters {
+		filters[i].prevX = apu.filters[i].prevX
+		filters[i].prevY = apu.filters[i].prevY
+	}
+	apu.filters = filters
+}
+
+// Mixes a cartridge sound source into the output, nil removes it
+func (apu *APU) SetExpansion(source func() float32) {
+	apu.expansion = source
+}
+
+func (apu *APU) SetRegion(region timing.Region) {
+	apu.cpuFrequency = region.CPUFrequency()
+	if region == timing.PAL {
+		apu.frameSteps = frameStepsPAL
+		apu.noise.periods = &noiseTablePAL
+		apu.dmc.periods = &dmcTablePAL
+	} else {
+		apu.frameSteps = frameStepsNTSC
+		apu.noise.periods = &noiseTable
+		apu.dmc.periods = &dmcTable
+	}
+}
+
+func (apu *APU) SampleRate() float64 {
+	return apu.sampleRate
+}
+
+func (apu *APU) CPUWrite(addr uint16, data uint8) {
+	switch {
+	case addr >= 0x4000 && addr <= 0x4003:
+		apu.pulse1.write(addr&0x0003, data)
+	case addr >= 0x4004 && addr <= 0x4007:
+		apu.pulse2.write(addr&0x0003, data)
+	case addr >= 0x4008 && addr <= 0x400B:
+		apu.triangle.write(addr&0x0003, data)
+	case addr >= 0x400C && addr <= 0x400F:
+		apu.noise.write(addr&0x0003, data)
+	case addr >= 0x4010 && addr <= 0x4013:
+		apu.dmc.write(addr&0x0003, data)
+	case addr == 0x4015:
+		apu.pulse1.length.setEnabled(data&0x01 != 0)
+		apu.pulse2.length.setEnabled(data&0x02 != 0)
+		apu.triangle.length.setEnabled(data&0x04 != 0)
+		apu.noise.length.setEnabled(data&0x08 != 0)
+		apu.dmc.setEnabled(data&0x10 != 0)
+	case addr == 0x4017:
+		apu.frameMode = data >> 7
+		apu.frameInhibit = data&0x40 != 0
+		if apu.frameInhibit {
+			apu.frameIRQ = false
+		}
+		// The sequencer restarts 3 or 4 CPU cycles after the write
+		if apu.cycle%2 == 0 {
+			apu.frameReset = 3
+		} else {
+			apu.frameReset = 4
+		}
+	}
+}
+
+func (apu *APU) CPURead(addr uint16, readOnly bool) uint8 {
+	var data uint8 = 0x00
+	if addr != 0x4015 {
+		return data
+	}
+	if apu.pulse1.length.active() {
+		data |= 0x01
+	}
+	if apu.pulse2.length.active() {
+		data |= 0x02
+	}
+	if apu.triangle.length.active() {
+		data |= 0x04
+	}
+	if apu.noise.length.active() {
+		data |= 0x08
+	}
+	if apu.dmc.bytesRemaining > 0 {
+		data |= 0x10
+	}
+	if apu.frameIRQ {
+		data |= 0x40
+	}
+	if apu.dmc.irq {
+		data |= 0x80
+	}
+	if !readOnly {
+		apu.frameIRQ = false
+	}
+	return data
+}
+
+// Clocked once per CPU cycle
+func (apu *APU) Clock() {
+	apu.clockFrameCounter()
+	apu.triangle.clockTimer()
+	if apu.cycle%2 == 1 {
+		apu.pulse1.clockTimer()
+		apu.pulse2.clockTimer()
+	}
+	apu.noise.clockTimer()
+	if apu.bus != nil {
+		apu.dmc.clockTimer(apu.bus)
+	}
+	apu.cycle++
+
+	apu.sampleClock += apu.sampleRate
+	if apu.sampleClock >= apu.cpuFrequency {
+		apu.sampleClock -= apu.cpuFrequency
+		sample := apu.filter(apu.mix())
+		if len(apu.samples) < maxPendingSamples {
+			apu.samples = append(apu.samples, sample)
+		}
+	}
+}
+
+func (apu *APU) clockFrameCounter() {
+	if apu.frameReset > 0 {
+		apu.frameReset--
+		if apu.frameReset == 0 {
+			apu.frameCounter = 0
+			if apu.frameMode == 1 {
+				apu.quarterFrame()
+				apu.halfFrame()
+			}
+		}
+	}
+	apu.frameCounter++
+	steps := apu.frameSteps
+	switch apu.frameCounter {
+	case steps[0]:
+		apu.quarterFrame()
+	case steps[1]:
+		apu.quarterFrame()
+		apu.halfFrame()
+	case steps[2]:
+		apu.quarterFrame()
+	case steps[3]:
+		if apu.frameMode == 0 {
+			apu.raiseFrameIRQ()
+		}
+	case steps[4]:
+		if apu.frameMode == 0 {
+			apu.quarterFrame()
+			apu.halfFrame()
+			apu.raiseFrameIRQ()
+		}
+	case steps[5]:
+		if apu.frameMode == 0 {
+			apu.raiseFrameIRQ()
+			apu.frameCounter = 0
+		}
+	case steps[6]:
+		apu.quarterFrame()
+		apu.halfFrame()
+	case steps[7]:
+		apu.frameCounter = 0
+	}
+}
+
+func (apu *APU) raiseFrameIRQ() {
+	if !apu.frameInhibit {
+		apu.frameIRQ = true
+	}
+}
+
+func (apu *APU) quarterFrame() {
+	apu.pulse1.envelope.clock()
+	apu.pulse2.envelope.clock()
+	apu.noise.envelope.clock()
+	apu.triangle.clockLinear()
+}
+
+func (apu *APU) halfFrame() {
+	apu.pulse1.length.clock()
+	apu.pulse2.length.clock()
+	apu.triangle.length.clock()
+	apu.noise.length.clock()
+	apu.pulse1.clockSweep()
+	apu.pulse2.clockSweep()
+}
+
+func (apu *APU) mix() float32 {
+	p := apu.pulseTable[apu.pulse1.output()+apu.pulse2.output()]
+	tnd := apu.tndTable[3*int(apu.triangle.output())+2*int(apu.noise.output())+int(apu.dmc.output())]
+	if apu.expansion != nil {
+		return p + tnd + apu.expansion()
+	}
+	return p + tnd
+}
+
+func (apu *APU) filter(sample float32) float32 {
+	for i := range apu.filters {
+		sample = apu.filters[i].step(sample)
+	}
+	return sample
+}
+
+// Moves up to len(buf) pending samples into buf and returns the count
+func (apu *APU) ReadSamples(buf []float32) int {
+	n := copy(buf, apu.samples)
+	apu.samples = append(apu.samples[:0], apu.samples[n:]...)
+	return n
+}
+
+func (apu *APU) PendingSamples() int {
+	return len(apu.samples)
+}
+
+func (apu *APU) IRQ() bool {
+	return apu.frameIRQ || apu.dmc.irq
+}
+
+// Returns the CPU cycles stolen by DMC sample fetches since the last call
+func (apu *APU) DMCStall() uint8 {
+	stall := apu.dmc.stall
+	apu.dmc.stall = 0
+	return stall
+}
+
+func (apu *APU) Reset() {
+	apu.CPUWrite(0x4015, 0x00)
+	apu.frameIRQ = false
+	apu.dmc.irq = false
+	apu.frameCounter = 0
+	apu.frameReset = 0
+	apu.samples = apu.samples[:0]
+}
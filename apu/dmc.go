@@ -0,0 +1,132 @@
+package apu
+
+var dmcTable = [16]uint16{
+	428, 380, 340, 320, 286, 254, 226, 214, 190, 160, 142, 128, 106, 84, 72, 54,
+}
+
+var dmcTablePAL = [16]uint16{
+	398, 354, 316, 298, 276, 236, 210, 198, 176, 148, 132, 118, 98, 78, 66, 50,
+}
+
+type dmc struct {
+	irqEnabled     bool
+	irq            bool
+	loop           bool
+	timer          uint16
+	period         uint16
+	periods        *[16]uint16
+	level          uint8
+	sampleAddr     uint16
+	sampleLength   uint16
+	currentAddr    uint16
+	bytesRemaining uint16
+	buffer         uint8
+	bufferEmpty    bool
+	shift          uint8
+	bitsRemaining  uint8
+	silence        bool
+	stall          uint8
+}
+
+func makeDMC() dmc {
+	return dmc{
+		period:        dmcTable[0],
+		periods:       &dmcTable,
+		sampleAddr:    0xC000,
+		sampleLength:  1,
+		bufferEmpty:   true,
+		bitsRemaining: 8,
+		silence:       true,
+	}
+}
+
+func (d *dmc) write(reg uint16, data uint8) {
+	switch reg {
+	case 0:
+		d.irqEnabled = data&0x80 != 0
+		d.loop = data&0x40 != 0
+		d.period = d.periods[data&0x0F]
+		if !d.irqEnabled {
+			d.irq = false
+		}
+	case 1:
+		d.level = data & 0x7F
+	case 2:
+		d.sampleAddr = 0xC000 | (uint16(data) << 6)
+	case 3:
+		d.sampleLength = (uint16(data) << 4) | 0x0001
+	}
+}
+
+func (d *dmc) setEnabled(enabled bool) {
+	d.irq = false
+	if !enabled {
+		d.bytesRemaining = 0
+	} else if d.bytesRemaining == 0 {
+		d.restart()
+	}
+}
+
+func (d *dmc) restart() {
+	d.currentAddr = d.sampleAddr
+	d.bytesRemaining = d.sampleLength
+}
+
+// Refills the sample buffer from CPU memory, stalling the CPU while the
+// DMA unit owns the bus
+func (d *dmc) fetch(bus Bus) {
+	if !d.bufferEmpty || d.bytesRemaining == 0 {
+		return
+	}
+	d.stall += 4
+	d.buffer = bus.Read(d.currentAddr, false)
+	d.bufferEmpty = false
+	if d.currentAddr == 0xFFFF {
+		d.currentAddr = 0x8000
+	} else {
+		d.currentAddr++
+	}
+	d.bytesRemaining--
+	if d.bytesRemaining == 0 {
+		if d.loop {
+			d.restart()
+		} else if d.irqEnabled {
+			d.irq = true
+		}
+	}
+}
+
+func (d *dmc) clockTimer(bus Bus) {
+	d.fetch(bus)
+	if d.timer > 0 {
+		d.timer--
+		return
+	}
+	d.timer = d.period - 1
+	if !d.silence {
+		if d.shift&0x01 != 0 {
+			if d.level <= 125 {
+				d.level += 2
+			}
+		} else if d.level >= 2 {
+			d.level -= 2
+		}
+	}
+	d.shift >>= 1
+	d.bitsRemaining--
+	if d.bitsRemaining == 0 {
+		d.bitsRemaining = 8
+		if d.bufferEmpty {
+			d.silence = true
+		} else {
+			d.silence = false
+			d.shift = d.buffer
+			d.bufferEmpty = true
+			d.fetch(bus)
+		}
+	}
+}
+
+func (d *dmc) output() uint8 {
+	return d.level
+}
@@ -0,0 +1,74 @@
+package apu
+
+var lengthTable = [32]uint8{
+	10, 254, 20, 2, 40, 4, 80, 6, 160, 8, 60, 10, 14, 12, 26, 14,
+	12, 16, 24, 18, 48, 20, 96, 22, 192, 24, 72, 26, 16, 28, 32, 30,
+}
+
+type envelope struct {
+	start    bool
+	loop     bool
+	constant bool
+	volume   uint8
+	divider  uint8
+	decay    uint8
+}
+
+func (e *envelope) write(data uint8) {
+	e.loop = data&0x20 != 0
+	e.constant = data&0x10 != 0
+	e.volume = data & 0x0F
+}
+
+func (e *envelope) clock() {
+	if e.start {
+		e.start = false
+		e.decay = 15
+		e.divider = e.volume
+	} else if e.divider == 0 {
+		e.divider = e.volume
+		if e.decay > 0 {
+			e.decay--
+		} else if e.loop {
+			e.decay = 15
+		}
+	} else {
+		e.divider--
+	}
+}
+
+func (e *envelope) output() uint8 {
+	if e.constant {
+		return e.volume
+	}
+	return e.decay
+}
+
+type lengthCounter struct {
+	enabled bool
+	halt    bool
+	counter uint8
+}
+
+func (l *lengthCounter) load(index uint8) {
+	if l.enabled {
+		l.counter = lengthTable[index&0x1F]
+	}
+}
+
+func (l *lengthCounter) setEnabled(enabled bool) {
+	l.enabled = enabled
+	if !enabled {
+		l.counter = 0
+	}
+}
+
+func (l *lengthCounter) clock() {
+	if !l.halt && l.counter > 0 {
+		l.counter--
+	}
+}
+
+func (l *lengthCounter) active() bool {
+	return l.counter > 0
+}
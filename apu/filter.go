@@ -0,0 +1,40 @@
+package apu
+
+import "math"
+
+// First order IIR filter, the NES output stage is roughly two high-pass
+// filters at 90 Hz and 440 Hz followed by a low-pass at 14 kHz
+type filter struct {
+	b0    float32
+	b1    float32
+	a1    float32
+	prevX float32
+	prevY float32
+}
+
+func makeLowPass(sampleRate float64, cutoff float64) filter {
+	c := sampleRate / math.Pi / cutoff
+	a0 := 1 / (1 + c)
+	return filter{
+		b0: float32(a0),
+		b1: float32(a0),
+		a1: float32((1 - c) * a0),
+	}
+}
+
+func makeHighPass(sampleRate float64, cutoff float64) filter {
+	c := sampleRate / math.Pi / cutoff
+	a0 := 1 / (1 + c)
+	return filter{
+		b0: float32(c * a0),
+		b1: float32(-c * a0),
+		a1: float32((1 - c) * a0),
+	}
+}
+
+func (f *filter) step(x float32) float32 {
+	y := f.b0*x + f.b1*f.prevX - f.a1*f.prevY
+	f.prevX = x
+	f.prevY = y
+	return y
+}
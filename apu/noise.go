@@ -0,0 +1,58 @@
+package apu
+
+var noiseTable = [16]uint16{
+	4, 8, 16, 32, 64, 96, 128, 160, 202, 254, 380, 508, 762, 1016, 2034, 4068,
+}
+
+var noiseTablePAL = [16]uint16{
+	4, 8, 14, 30, 60, 88, 118, 148, 188, 236, 354, 472, 708, 944, 1890, 3778,
+}
+
+type noise struct {
+	mode     bool
+	shift    uint16
+	timer    uint16
+	period   uint16
+	periods  *[16]uint16
+	envelope envelope
+	length   lengthCounter
+}
+
+func makeNoise() noise {
+	return noise{shift: 0x0001, period: noiseTable[0], periods: &noiseTable}
+}
+
+func (n *noise) write(reg uint16, data uint8) {
+	switch reg {
+	case 0:
+		n.length.halt = data&0x20 != 0
+		n.envelope.write(data)
+	case 2:
+		n.mode = data&0x80 != 0
+		n.period = n.periods[data&0x0F]
+	case 3:
+		n.length.load(data >> 3)
+		n.envelope.start = true
+	}
+}
+
+func (n *noise) clockTimer() {
+	if n.timer == 0 {
+		n.timer = n.period - 1
+		tap := uint16(1)
+		if n.mode {
+			tap = 6
+		}
+		feedback := (n.shift & 0x0001) ^ ((n.shift >> tap) & 0x0001)
+		n.shift = (n.shift >> 1) | (feedback << 14)
+	} else {
+		n.timer--
+	}
+}
+
+func (n *noise) output() uint8 {
+	if !n.length.active() || n.shift&0x0001 != 0 {
+		return 0
+	}
+	return n.envelope.output()
+}
@@ -0,0 +1,96 @@
+package apu
+
+var dutyTable = [4][8]uint8{
+	{0, 1, 0, 0, 0, 0, 0, 0},
+	{0, 1, 1, 0, 0, 0, 0, 0},
+	{0, 1, 1, 1, 1, 0, 0, 0},
+	{1, 0, 0, 1, 1, 1, 1, 1},
+}
+
+type pulse struct {
+	channel      uint8 // 1 or 2, pulse 1 negates with ones' complement
+	duty         uint8
+	sequence     uint8
+	timer        uint16
+	period       uint16
+	envelope     envelope
+	length       lengthCounter
+	sweepEnabled bool
+	sweepPeriod  uint8
+	sweepNegate  bool
+	sweepShift   uint8
+	sweepDivider uint8
+	sweepReload  bool
+}
+
+func makePulse(channel uint8) pulse {
+	return pulse{channel: channel}
+}
+
+func (p *pulse) write(reg uint16, data uint8) {
+	switch reg {
+	case 0:
+		p.duty = data >> 6
+		p.length.halt = data&0x20 != 0
+		p.envelope.write(data)
+	case 1:
+		p.sweepEnabled = data&0x80 != 0
+		p.sweepPeriod = (data >> 4) & 0x07
+		p.sweepNegate = data&0x08 != 0
+		p.sweepShift = data & 0x07
+		p.sweepReload = true
+	case 2:
+		p.period = (p.period & 0x0700) | uint16(data)
+	case 3:
+		p.period = (p.period & 0x00FF) | (uint16(data&0x07) << 8)
+		p.length.load(data >> 3)
+		p.sequence = 0
+		p.envelope.start = true
+	}
+}
+
+func (p *pulse) clockTimer() {
+	if p.timer == 0 {
+		p.timer = p.period
+		p.sequence = (p.sequence + 1) & 0x07
+	} else {
+		p.timer--
+	}
+}
+
+func (p *pulse) sweepTarget() uint16 {
+	change := p.period >> p.sweepShift
+	if !p.sweepNegate {
+		return p.period + change
+	}
+	if p.channel == 1 {
+		change++
+	}
+	if change > p.period {
+		return 0
+	}
+	return p.period - change
+}
+
+func (p *pulse) muted() bool {
+	return p.period < 8 || p.sweepTarget() > 0x07FF
+}
+
+func (p *pulse) clockSweep() {
+	if p.sweepDivider == 0 && p.sweepEnabled && p.sweepShift > 0 && !p.muted() {
+		p.period = p.sweepTarget()
+	}
+	if p.sweepDivider == 0 || p.sweepReload {
+		p.sweepDivider = p.sweepPeriod
+		p.sweepReload = false
+	} else {
+		p.sweepDivider--
+	}
+}
+
+func (p *pulse) output() uint8 {
+	if !p.length.active() || p.muted() || dutyTable[p.duty][p.sequence] == 0 {
+		return 0
+	}
+	return p.envelope.output()
+}
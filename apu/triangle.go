@@ -0,0 +1,58 @@
+package apu
+
+var triangleTable = [32]uint8{
+	15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0,
+	0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+}
+
+type triangle struct {
+	sequence      uint8
+	timer         uint16
+	period        uint16
+	length        lengthCounter
+	control       bool
+	linearReload  uint8
+	linearCounter uint8
+	reloadFlag    bool
+}
+
+func (t *triangle) write(reg uint16, data uint8) {
+	switch reg {
+	case 0:
+		t.control = data&0x80 != 0
+		t.length.halt = t.control
+		t.linearReload = data & 0x7F
+	case 2:
+		t.period = (t.period & 0x0700) | uint16(data)
+	case 3:
+		t.period = (t.period & 0x00FF) | (uint16(data&0x07) << 8)
+		t.length.load(data >> 3)
+		t.reloadFlag = true
+	}
+}
+
+func (t *triangle) clockTimer() {
+	if t.timer == 0 {
+		t.timer = t.period
+		if t.length.active() && t.linearCounter > 0 {
+			t.sequence = (t.sequence + 1) & 0x1F
+		}
+	} else {
+		t.timer--
+	}
+}
+
+func (t *triangle) clockLinear() {
+	if t.reloadFlag {
+		t.linearCounter = t.linearReload
+	} else if t.linearCounter > 0 {
+		t.linearCounter--
+	}
+	if !t.control {
+		t.reloadFlag = false
+	}
+}
+
+func (t *triangle) output() uint8 {
+	return triangleTable[t.sequence]
+}
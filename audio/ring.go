@@ -0,0 +1,65 @@
+package audio
+
+import "sync"
+
+// Fixed size FIFO shared between the emulation and the audio device
+type Ring struct {
+	buf   []float32
+	read  int
+	write int
+	count int
+	mu    sync.Mutex
+	cond  *sync.Cond
+}
+
+func NewRing(capacity int) *Ring {
+	r := &Ring{
+		buf:   make([]float32, capacity),
+		read:  0,
+		write: 0,
+		count: 0,
+	}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// Writes all samples, blocking while the ring is full
+func (r *Ring) Write(samples []float32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range samples {
+		for r.count == len(r.buf) {
+			r.cond.Wait()
+		}
+		r.buf[r.write] = s
+		r.write = (r.write + 1) % len(r.buf)
+		r.count++
+	}
+}
+
+// Reads up to len(samples) samples without blocking and returns the count
+func (r *Ring) Read(samples []float32) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for n < len(samples) && r.count > 0 {
+		samples[n] = r.buf[r.read]
+		r.read = (r.read + 1) % len(r.buf)
+		r.count--
+		n++
+	}
+	if n > 0 {
+		r.cond.Broadcast()
+	}
+	return n
+}
+
+func (r *Ring) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+func (r *Ring) Cap() int {
+	return len(r.buf)
+}
@@ -0,0 +1,29 @@
+package audio
+
+type Sink interface {
+	Write(samples []float32)
+	Fill() float64 // Buffer occupancy from 0 to 1
+	SampleRate() int
+	Close()
+}
+
+// Discards every sample and never blocks, for headless and test runs
+type NullSink struct {
+	rate int
+}
+
+func NewNullSink(rate int) *NullSink {
+	return &NullSink{rate: rate}
+}
+
+func (s *NullSink) Write(samples []float32) {}
+
+func (s *NullSink) Fill() float64 {
+	return 0.5
+}
+
+func (s *NullSink) SampleRate() int {
+	return s.rate
+}
+
+func (s *NullSink) Close() {}
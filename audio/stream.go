@@ -0,0 +1,49 @@
+package audio
+
+// Maximum resampling ratio change used to steer the sink buffer to half
+// full, small enough to be inaudible as pitch drift
+const maxRateDelta = 0.005
+
+type Source interface {
+	ReadSamples(buf []float32) int
+	SetSampleRate(rate float64)
+}
+
+type Stream struct {
+	source Source
+	sink   Sink
+	buf    []float32
+}
+
+func NewStream(source Source, sink Sink) *Stream {
+	source.SetSampleRate(float64(sink.SampleRate()))
+	return &Stream{
+		source: source,
+		sink:   sink,
+		buf:    make([]float32, 4096),
+	}
+}
+
+// Moves every pending sample from the source to the sink, blocking when the
+// sink is full so the audio device paces the emulation
+func (s *Stream) Update() {
+	for {
+		n := s.source.ReadSamples(s.buf)
+		if n == 0 {
+			break
+		}
+		s.sink.Write(s.buf[:n])
+	}
+	ratio := 1 + maxRateDelta*(1-2*s.sink.Fill())
+	s.source.SetSampleRate(float64(s.sink.SampleRate()) * ratio)
+}
+
+func (s *Stream) Sink() Sink {
+	return s.sink
+}
+
+// Drops pending samples, used while running faster than real time
+func (s *Stream) Skip() {
+	for s.source.ReadSamples(s.buf) > 0 {
+	}
+}
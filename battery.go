@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/laranc/emuNES/cartridge"
+)
+
+const saveInterval = 300 // Frames between periodic saves
+
+// Persists battery backed PRG-RAM to a .sav file next to the ROM, a nil
+// battery is a cartridge without one
+type battery struct {
+	rom      *cartridge.ROM
+	path     string
+	lastSave uint64
+}
+
+func newBattery(rom *cartridge.ROM, romPath string) *battery {
+	if !rom.Battery() {
+		return nil
+	}
+	b := &battery{
+		rom:  rom,
+		path: strings.TrimSuffix(romPath, filepath.Ext(romPath)) + ".sav",
+	}
+	err := rom.LoadSave(b.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Println("loading save:", err)
+	}
+	return b
+}
+
+// Saves every saveInterval frames when the RAM has changed
+func (b *battery) update(frame uint64) {
+	if b == nil || frame-b.lastSave < saveInterval {
+		return
+	}
+	b.lastSave = frame
+	b.save()
+}
+
+func (b *battery) save() {
+	if b == nil || !b.rom.SaveDirty() {
+		return
+	}
+	if err := b.rom.WriteSave(b.path); err != nil {
+		log.Println("writing save:", err)
+	}
+}
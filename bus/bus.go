@@ -1,18 +1,48 @@
 package bus
 
 import (
+	"image"
+	"io"
+
+	"github.com/laranc/emuNES/apu"
 	"github.com/laranc/emuNES/cartridge"
 	"github.com/laranc/emuNES/mos6502"
 	"github.com/laranc/emuNES/rp2C02"
-	"github.com/veandco/go-sdl2/sdl"
+	"github.com/laranc/emuNES/timing"
 )
 
+// Input device plugged into a controller port, Read returns bits D0-D4
+type Controller interface {
+	Write(data uint8)
+	Read() uint8
+}
+
 type Bus struct {
 	cpu          *mos6502.CPU
 	wram         [2048]uint8 // 2 KB
 	ppu          *rp2C02.PPU
+	apu          *apu.APU
 	rom          *cartridge.ROM
+	ports        [2]Controller
+	openBus      uint8
 	clockCounter int
+	cpuPhase     int
+	cpuCycles    uint64
+	region       timing.Region
+	dmaPage      uint8
+	dmaAddr      uint8
+	dmaData      uint8
+	dmaHalt      bool
+	dmaAligned   bool
+	dmaTransfer  bool
+	nmiLine      bool
+	nmiPending   bool
+	cpuStall     int
+	vs           bool // Vs. System cabinet inputs on $4016/$4017/$4020
+	dipSwitches  uint8
+	coins        [2]bool
+	service      bool
+	coinCounter  uint8
 }
 
 func NewBus() *Bus {
@@ -20,31 +50,84 @@ func NewBus() *Bus {
 		cpu:          mos6502.NewCPU(),
 		wram:         [2048]uint8{},
 		ppu:          rp2C02.NewPPU(),
+		apu:          apu.NewAPU(),
 		rom:          nil,
+		ports:        [2]Controller{nil, nil},
+		openBus:      0x00,
 		clockCounter: 0,
+		cpuPhase:     0,
+		cpuCycles:    0,
+		region:       timing.NTSC,
+		dmaPage:      0x00,
+		dmaAddr:      0x00,
+		dmaData:      0x00,
+		dmaHalt:      false,
+		dmaAligned:   false,
+		dmaTransfer:  false,
+		nmiLine:      false,
+		nmiPending:   false,
+		cpuStall:     0,
+		vs:           false,
+		dipSwitches:  0x00,
+		coins:        [2]bool{false, false},
+		service:      false,
+		coinCounter:  0x00,
 	}
 	b.cpu.ConnectBus(b)
+	b.apu.ConnectBus(b)
 	return b
 }
 
 func (b *Bus) Write(addr uint16, data uint8) {
+	b.openBus = data
 	if b.rom.CPUWrite(addr, data) {
 		// Write to the cartridge or pass and write to the wram
 	} else if addr <= 0x1FFF {
-		b.wram[addr] = data
+		b.wram[addr&0x07FF] = data
 	} else if addr >= 0x2000 && addr <= 0x3FFF {
-		b.ppu.BusWrite(addr*0x0007, data)
+		b.ppu.BusWrite(addr&0x0007, data)
+	} else if (addr >= 0x4000 && addr <= 0x4013) || addr == 0x4015 || addr == 0x4017 {
+		b.apu.CPUWrite(addr, data)
+	} else if addr == 0x4016 {
+		for _, c := range b.ports {
+			if c != nil {
+				c.Write(data)
+			}
+		}
+	} else if addr == 0x4020 && b.vs {
+		b.coinCounter = data
+	} else if addr == 0x4014 {
+		b.dmaPage = data
+		b.dmaAddr = 0x00
+		b.dmaHalt = true
+		b.dmaAligned = false
+		b.dmaTransfer = true
 	}
 }
 
 func (b *Bus) Read(addr uint16, readOnly bool) uint8 {
-	var data uint8 = 0x00
-	if b.rom.CPURead(addr, &data) {
+	// Unmapped addresses return whatever was last on the data bus
+	var data uint8 = b.openBus
+	if b.rom.CPURead(addr, &data, readOnly) {
 		// Read from the cartridge or pass and read from the wram
 	} else if addr <= 0x1FFF {
-		return b.wram[addr&0x07FF]
+		data = b.wram[addr&0x07FF]
 	} else if addr >= 0x2000 && addr <= 0x3FFF {
 		data = b.ppu.BusRead(addr&0x0007, readOnly)
+	} else if addr == 0x4015 {
+		data = b.apu.CPURead(addr, readOnly)
+	} else if addr == 0x4016 || addr == 0x4017 {
+		// Only the low bits are driven, the rest is left over on the data bus
+		data = b.openBus & 0xE0
+		if c := b.ports[addr&0x0001]; c != nil && !readOnly {
+			data |= c.Read() & 0x1F
+		}
+		if b.vs {
+			data = b.vsInputs(addr, data)
+		}
+	}
+	if !readOnly {
+		b.openBus = data
 	}
 	return data
 }
@@ -52,38 +135,130 @@ func (b *Bus) Read(addr uint16, readOnly bool) uint8 {
 func (b *Bus) InsertCartridge(rom *cartridge.ROM) {
 	b.rom = rom
 	b.ppu.ConnectCartridge(rom)
+	b.vs = rom.VsSystem()
+	if b.vs {
+		b.ppu.SetPalette(vsPalette(rom.Info().VsPPUType))
+	} else {
+		b.ppu.SetPalette(rp2C02.PaletteRP2C02)
+	}
+	b.SetExpansionAudio(rom.ExpansionAudio())
+}
+
+func (b *Bus) SetExpansionAudio(source func() float32) {
+	b.apu.SetExpansion(source)
 }
 
-func (b *Bus) ConnectRenderer(renderer *sdl.Renderer) {
-	b.ppu.ConnectRenderer(renderer)
+func (b *Bus) ConnectController(port int, c Controller) {
+	b.ports[port] = c
+}
+
+func (b *Bus) SetRegion(region timing.Region) {
+	b.region = region
+	b.ppu.SetRegion(region)
+	b.apu.SetRegion(region)
 }
 
 func (b *Bus) Reset() {
 	b.rom.Reset()
 	b.cpu.Reset()
 	b.ppu.Reset()
+	b.apu.Reset()
 	b.clockCounter = 0
+	b.cpuPhase = 0
+	b.cpuCycles = 0
+	b.dmaTransfer = false
+	b.nmiLine = false
+	b.nmiPending = false
+	b.cpuStall = 0
 }
 
 func (b *Bus) Clock() {
-	if b.clockCounter%3 == 0 {
-		b.cpu.Clock()
+	b.ppu.Clock()
+	// The CPU runs every 3 dots, or 3.2 on PAL
+	num, den := b.region.PPUDivider()
+	b.cpuPhase += den
+	if b.cpuPhase >= num {
+		b.cpuPhase -= num
+		b.cpuClock()
+		b.cpuCycles++
 	}
 	b.clockCounter++
 }
 
-func (b *Bus) PPUClock() {
-	b.ppu.Clock()
+func (b *Bus) cpuClock() {
+	b.rom.CPUClock()
+	b.apu.Clock()
+	b.cpuStall += int(b.apu.DMCStall())
+	if b.dmaTransfer {
+		b.dmaClock(b.cpuCycles)
+	} else if b.cpuStall > 0 {
+		b.cpuStall--
+	} else {
+		if b.nmiPending && b.cpu.Complete() {
+			b.nmiPending = false
+			b.cpu.NMI()
+		} else if (b.apu.IRQ() || b.rom.IRQ()) && b.cpu.Complete() {
+			b.cpu.IRQ()
+		}
+		b.cpu.Clock()
+	}
+	// NMI is edge triggered, so enabling it during vblank fires again
+	nmi := b.ppu.NMILine()
+	if nmi && !b.nmiLine {
+		b.nmiPending = true
+	}
+	b.nmiLine = nmi
+}
+
+// OAM DMA halts the CPU for one cycle, waits one more if it would start on a
+// put cycle, then alternates 256 get/put pairs: 513 or 514 cycles in total
+func (b *Bus) dmaClock(cycle uint64) {
+	if b.dmaHalt {
+		b.dmaHalt = false
+	} else if cycle%2 == 0 {
+		b.dmaData = b.Read(uint16(b.dmaPage)<<8|uint16(b.dmaAddr), false)
+		b.dmaAligned = true
+	} else if b.dmaAligned {
+		b.ppu.WriteOAM(b.dmaData)
+		b.dmaAddr++
+		if b.dmaAddr == 0x00 {
+			b.dmaTransfer = false
+		}
+	}
 }
 
 func (b *Bus) PPUFrameComplete() bool {
 	complete := b.ppu.FrameComplete()
 	if complete {
-		b.ppu.Reset()
+		b.ppu.ClearFrameComplete()
 	}
 	return complete
 }
 
+func (b *Bus) SetSampleRate(rate float64) {
+	b.apu.SetSampleRate(rate)
+}
+
+func (b *Bus) ReadSamples(buf []float32) int {
+	return b.apu.ReadSamples(buf)
+}
+
+func (b *Bus) CPUComplete() bool {
+	return b.cpu.Complete()
+}
+
+func (b *Bus) Screen() *image.RGBA {
+	return b.ppu.GetScreen()
+}
+
+func (b *Bus) CPUSetPC(pc uint16) {
+	b.cpu.SetPC(pc)
+}
+
+func (b *Bus) CPUSetTrace(w io.Writer) {
+	b.cpu.SetTrace(w)
+}
+
 func (b *Bus) CPUGetA() uint8 {
 	return b.cpu.GetA()
 }
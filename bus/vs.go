@@ -0,0 +1,53 @@
+package bus
+
+import "github.com/laranc/emuNES/rp2C02"
+
+// Colour generator for a Vs. System PPU type from the NES 2.0 header, the
+// RP2C03 and RC2C05 variants all share the RGB palette
+func vsPalette(ppuType uint8) uint8 {
+	switch ppuType {
+	case 2:
+		return rp2C02.PaletteRP2C04_0001
+	case 3:
+		return rp2C02.PaletteRP2C04_0002
+	case 4:
+		return rp2C02.PaletteRP2C04_0003
+	case 5:
+		return rp2C02.PaletteRP2C04_0004
+	default:
+		return rp2C02.PaletteRP2C03
+	}
+}
+
+// Adds the cabinet inputs to a controller port read. $4016 carries the
+// service button in D2, DIP switches 1-2 in D3-D4 and the coin slots in
+// D5-D6 with D7 low on the main CPU, $4017 carries DIP switches 3-8 in D2-D7
+func (b *Bus) vsInputs(addr uint16, data uint8) uint8 {
+	if addr == 0x4016 {
+		data = data&0x01 | (b.dipSwitches&0x03)<<3
+		if b.service {
+			data |= 0x04
+		}
+		if b.coins[0] {
+			data |= 0x20
+		}
+		if b.coins[1] {
+			data |= 0x40
+		}
+		return data
+	}
+	return data&0x01 | b.dipSwitches&0xFC
+}
+
+// Bit 0 is DIP switch 1
+func (b *Bus) SetDIPSwitches(dip uint8) {
+	b.dipSwitches = dip
+}
+
+func (b *Bus) SetCoin(slot int, inserted bool) {
+	b.coins[slot] = inserted
+}
+
+func (b *Bus) SetService(pressed bool) {
+	b.service = pressed
+}
@@ -0,0 +1,39 @@
+package cartridge
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrBadMagic = errors.New("cartridge: missing iNES signature")
+
+// The header declares no PRG ROM or a PRG/CHR ROM too large to be real
+var ErrBadSize = errors.New("cartridge: bad PRG or CHR ROM size")
+
+// The file ended before a section of the declared size was read
+type TruncatedError struct {
+	Section string
+	Want    int
+	Got     int
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("cartridge: truncated %s, want %d bytes, got %d", e.Section, e.Want, e.Got)
+}
+
+type UnsupportedMapperError struct {
+	Mapper    uint16
+	Submapper uint8
+	Err       error // Set when the mapper exists but rejects the board variant
+}
+
+func (e *UnsupportedMapperError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("cartridge: unsupported mapper %d (submapper %d): %v", e.Mapper, e.Submapper, e.Err)
+	}
+	return fmt.Sprintf("cartridge: unsupported mapper %d (submapper %d)", e.Mapper, e.Submapper)
+}
+
+func (e *UnsupportedMapperError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,168 @@
+package cartridge
+
+import "github.com/laranc/emuNES/timing"
+
+const (
+	FormatINES uint8 = iota
+	FormatNES20
+)
+
+const (
+	TimingNTSC uint8 = iota
+	TimingPAL
+	TimingMulti
+	TimingDendy
+)
+
+const (
+	ConsoleNES uint8 = iota
+	ConsoleVsSystem
+	ConsolePlaychoice
+	ConsoleExtended
+)
+
+// Raw 16 byte iNES / NES 2.0 header, see https://www.nesdev.org/wiki/NES_2.0
+type Header struct {
+	Name      [4]byte
+	PrgChunks uint8
+	ChrChunks uint8
+	Flags6    uint8
+	Flags7    uint8
+	Flags8    uint8
+	Flags9    uint8
+	Flags10   uint8
+	Flags11   uint8
+	Flags12   uint8
+	Flags13   uint8
+	Flags14   uint8
+	Flags15   uint8
+}
+
+// Cartridge description decoded from either header format
+type Info struct {
+	Format          uint8
+	Mapper          uint16
+	Submapper       uint8
+	PRGROMSize      int
+	CHRROMSize      int
+	PRGRAMSize      int
+	PRGNVRAMSize    int
+	CHRRAMSize      int
+	CHRNVRAMSize    int
+	Mirror          uint8
+	FourScreen      bool
+	Battery         bool
+	Trainer         bool
+	Timing          uint8
+	ConsoleType     uint8
+	VsPPUType       uint8
+	VsHardwareType  uint8
+	ExtendedConsole uint8
+	MiscROMs        uint8
+	ExpansionDevice uint8
+}
+
+func (h *Header) Valid() bool {
+	return h.Name == [4]byte{'N', 'E', 'S', 0x1A}
+}
+
+func (h *Header) IsNES20() bool {
+	return h.Flags7&0x0C == 0x08
+}
+
+func (h *Header) Info() Info {
+	info := Info{
+		Mirror:     MirrorHorizontal,
+		FourScreen: h.Flags6&0x08 != 0,
+		Battery:    h.Flags6&0x02 != 0,
+		Trainer:    h.Flags6&0x04 != 0,
+		Mapper:     uint16(h.Flags6 >> 4),
+	}
+	if h.Flags6&0x01 != 0 {
+		info.Mirror = MirrorVertical
+	}
+	if h.IsNES20() {
+		info.Format = FormatNES20
+		info.Mapper |= uint16(h.Flags7&0xF0) | uint16(h.Flags8&0x0F)<<8
+		info.Submapper = h.Flags8 >> 4
+		info.PRGROMSize = romSize(h.PrgChunks, h.Flags9&0x0F, 16384)
+		info.CHRROMSize = romSize(h.ChrChunks, h.Flags9>>4, 8192)
+		info.PRGRAMSize = ramSize(h.Flags10 & 0x0F)
+		info.PRGNVRAMSize = ramSize(h.Flags10 >> 4)
+		info.CHRRAMSize = ramSize(h.Flags11 & 0x0F)
+		info.CHRNVRAMSize = ramSize(h.Flags11 >> 4)
+		info.Timing = h.Flags12 & 0x03
+		info.ConsoleType = h.Flags7 & 0x03
+		switch info.ConsoleType {
+		case ConsoleVsSystem:
+			info.VsPPUType = h.Flags13 & 0x0F
+			info.VsHardwareType = h.Flags13 >> 4
+		case ConsoleExtended:
+			info.ExtendedConsole = h.Flags13 & 0x0F
+		}
+		info.MiscROMs = h.Flags14 & 0x03
+		info.ExpansionDevice = h.Flags15 & 0x3F
+		return info
+	}
+
+	info.Format = FormatINES
+	// Old dumping tools wrote signatures such as "DiskDude!" over bytes 7-15,
+	// the upper mapper nibble is only trustworthy when the padding is clean
+	if h.Flags12 == 0 && h.Flags13 == 0 && h.Flags14 == 0 && h.Flags15 == 0 {
+		info.Mapper |= uint16(h.Flags7 & 0xF0)
+		info.ConsoleType = h.Flags7 & 0x03
+	}
+	info.PRGROMSize = int(h.PrgChunks) * 16384
+	info.CHRROMSize = int(h.ChrChunks) * 8192
+	if info.CHRROMSize == 0 {
+		info.CHRRAMSize = 8192
+	}
+	// Flags 8 counts 8 KB units, 0 meaning 8 KB for compatibility
+	prgRAM := int(h.Flags8) * 8192
+	if prgRAM == 0 {
+		prgRAM = 8192
+	}
+	if info.Battery {
+		info.PRGNVRAMSize = prgRAM
+	} else {
+		info.PRGRAMSize = prgRAM
+	}
+	if h.Flags9&0x01 != 0 {
+		info.Timing = TimingPAL
+	}
+	return info
+}
+
+// Largest PRG or CHR ROM Load accepts, the 12-bit unit counts stay below it
+const maxROMSize = 64 << 20
+
+// NES 2.0 sizes are either a 12-bit count of units or, when the MSB nibble is
+// $F, an exponent-multiplier pair: 2^E * (MM*2+1) bytes. Exponents are
+// clamped just past maxROMSize so the product can't overflow
+func romSize(lsb uint8, msb uint8, unit int) int {
+	if msb == 0x0F {
+		exponent := min(lsb>>2, 27)
+		multiplier := int(lsb&0x03)*2 + 1
+		return (1 << exponent) * multiplier
+	}
+	return (int(msb)<<8 | int(lsb)) * unit
+}
+
+// NES 2.0 RAM sizes are 64 << shift bytes, a shift of 0 meaning none
+func ramSize(shift uint8) int {
+	if shift == 0 {
+		return 0
+	}
+	return 64 << shift
+}
+
+func (info Info) Region() timing.Region {
+	switch info.Timing {
+	case TimingPAL:
+		return timing.PAL
+	case TimingDendy:
+		return timing.Dendy
+	default:
+		return timing.NTSC
+	}
+}
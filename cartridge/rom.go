@@ -1,136 +1,247 @@
 package cartridge
 
 import (
-	"encoding/binary"
+	"bytes"
 	"io"
-	"log"
 	"os"
 
 	"github.com/laranc/emuNES/mapper"
 )
 
 const (
-	MirrorHorizontal uint8 = iota
-	MirrorVertical
-	MirrorOnescreenLow
-	MirrorOnescreenHigh
+	MirrorHorizontal    = mapper.MirrorHorizontal
+	MirrorVertical      = mapper.MirrorVertical
+	MirrorOnescreenLow  = mapper.MirrorOnescreenLow
+	MirrorOnescreenHigh = mapper.MirrorOnescreenHigh
+	MirrorFourScreen    = mapper.MirrorFourScreen
 )
 
 type ROM struct {
 	prg        []uint8
 	chr        []uint8
 	imageValid bool
-	mapperID   uint8
-	prgBanks   uint8
-	chrBanks   uint8
+	info       Info
+	mapperID   uint16
 	mirror     uint8
+	vram       []uint8 // Four screen nametables
+	prgRAM     []uint8 // $6000-$7FFF work RAM, battery backed when info.Battery is set
+	ramDirty   bool
+	protection *vsProtection
 	mapper     mapper.Mapper
 }
 
-type Header struct {
-	Name       [4]byte
-	PrgChunks  uint8
-	ChrChunks  uint8
-	Mapper1    uint8
-	Mapper2    uint8
-	PrgRamSize uint8
-	TVSystem1  uint8
-	TVSystem2  uint8
-	Unused     [4]byte
-}
-
-func NewROM(file string) *ROM {
-	var rom *ROM = &ROM{}
+func NewROM(file string) (*ROM, error) {
 	f, err := os.Open(file)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer f.Close()
-	h := &Header{}
-	err = binary.Read(f, binary.NativeEndian, h)
-	if err != nil {
-		log.Fatal(err)
+	return Load(f)
+}
+
+func LoadBytes(data []byte) (*ROM, error) {
+	return Load(bytes.NewReader(data))
+}
+
+func Load(r io.Reader) (*ROM, error) {
+	var rom *ROM = &ROM{}
+	var raw [16]uint8
+	if err := readSection(r, raw[:], "header"); err != nil {
+		return nil, err
+	}
+	h := &Header{
+		Name:      [4]byte(raw[0:4]),
+		PrgChunks: raw[4],
+		ChrChunks: raw[5],
+		Flags6:    raw[6],
+		Flags7:    raw[7],
+		Flags8:    raw[8],
+		Flags9:    raw[9],
+		Flags10:   raw[10],
+		Flags11:   raw[11],
+		Flags12:   raw[12],
+		Flags13:   raw[13],
+		Flags14:   raw[14],
+		Flags15:   raw[15],
+	}
+	if !h.Valid() {
+		return nil, ErrBadMagic
+	}
+	rom.info = h.Info()
+	if rom.info.PRGROMSize <= 0 || rom.info.PRGROMSize > maxROMSize ||
+		rom.info.CHRROMSize < 0 || rom.info.CHRROMSize > maxROMSize {
+		return nil, ErrBadSize
 	}
 
-	if (h.Mapper1 & 0x04) != 0 {
-		f.Seek(512, io.SeekCurrent)
+	var trainer []uint8
+	if rom.info.Trainer {
+		trainer = make([]uint8, 512)
+		if err := readSection(r, trainer, "trainer"); err != nil {
+			return nil, err
+		}
+	}
+	rom.mapperID = rom.info.Mapper
+	rom.prg = make([]uint8, rom.info.PRGROMSize)
+	if err := readSection(r, rom.prg, "PRG ROM"); err != nil {
+		return nil, err
 	}
-	rom.mapperID = ((h.Mapper2 >> 4) << 4) | (h.Mapper1 >> 4)
-	fileType := 1
-	switch fileType {
-	case 1:
-		rom.prgBanks = h.PrgChunks
-		rom.prg = make([]uint8, int(rom.prgBanks)*16384)
-		_, err := f.Read(rom.prg)
-		if err != nil {
-			log.Fatal(err)
+	if rom.info.CHRROMSize > 0 {
+		rom.chr = make([]uint8, rom.info.CHRROMSize)
+		if err := readSection(r, rom.chr, "CHR ROM"); err != nil {
+			return nil, err
 		}
-		rom.chrBanks = h.ChrChunks
-		rom.chr = make([]uint8, int(rom.chrBanks)*8192)
-		_, err = f.Read(rom.chr)
-		if err != nil {
-			log.Fatal(err)
+	} else {
+		rom.chr = make([]uint8, max(rom.info.CHRRAMSize+rom.info.CHRNVRAMSize, 8192))
+	}
+	if size := rom.info.PRGRAMSize + rom.info.PRGNVRAMSize; size > 0 {
+		rom.prgRAM = make([]uint8, size)
+	}
+	if trainer != nil {
+		// The trainer is loaded at $7000
+		if len(rom.prgRAM) < 8192 {
+			rom.prgRAM = make([]uint8, 8192)
 		}
-	default:
-		break
+		copy(rom.prgRAM[0x1000:], trainer)
+	}
+	if rom.VsSystem() {
+		rom.protection = &vsProtection{hardware: rom.info.VsHardwareType}
+	}
+	constructor, ok := mapper.Lookup(rom.mapperID)
+	if !ok {
+		return nil, &UnsupportedMapperError{Mapper: rom.info.Mapper, Submapper: rom.info.Submapper}
 	}
-	switch rom.mapperID {
-	case 0:
-		rom.mapper = mapper.MakeMapper000(rom.prgBanks, rom.chrBanks)
-	default:
-		break
+	m, err := constructor(mapper.Config{
+		ID:         rom.info.Mapper,
+		Submapper:  rom.info.Submapper,
+		PRGROMSize: rom.info.PRGROMSize,
+		CHRROMSize: rom.info.CHRROMSize,
+		PRGRAMSize: rom.info.PRGRAMSize + rom.info.PRGNVRAMSize,
+		CHRRAMSize: len(rom.chr) - rom.info.CHRROMSize,
+		PRG:        rom.prg,
+		CHR:        rom.chr,
+		PRGRAM:     rom.prgRAM,
+	})
+	if err != nil {
+		return nil, &UnsupportedMapperError{Mapper: rom.info.Mapper, Submapper: rom.info.Submapper, Err: err}
 	}
+	rom.mapper = m
 	rom.imageValid = true
-	rom.mirror = MirrorHorizontal
-	return rom
+	rom.mirror = rom.info.Mirror
+	if rom.info.FourScreen {
+		rom.mirror = MirrorFourScreen
+		rom.vram = make([]uint8, 4096)
+	}
+	return rom, nil
+}
+
+func readSection(r io.Reader, buf []uint8, section string) error {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return &TruncatedError{Section: section, Want: len(buf), Got: n}
+	}
+	return err
 }
 
 func (rom *ROM) CPUWrite(addr uint16, data uint8) bool {
-	var mappedAddr uint32 = 0
-	if rom.mapper.CPUMapWrite(addr, &mappedAddr) {
-		rom.prg[mappedAddr] = data
+	if rom.mapper.CPUWrite(addr, data) {
+		if addr >= 0x6000 && addr <= 0x7FFF {
+			rom.ramDirty = true
+		}
 		return true
 	}
 	return false
 }
 
-func (rom *ROM) CPURead(addr uint16, data *uint8) bool {
-	var mappedAddr uint32 = 0
-	if rom.mapper.CPUMapRead(addr, &mappedAddr) {
-		*data = rom.prg[mappedAddr]
+func (rom *ROM) CPURead(addr uint16, data *uint8, readOnly bool) bool {
+	if rom.protection != nil && addr >= 0x5000 && addr <= 0x5FFF && rom.protection.read(addr, data, readOnly) {
+		return true
+	}
+	if d, ok := rom.mapper.CPURead(addr, readOnly); ok {
+		*data = d
 		return true
 	}
 	return false
 }
 
 func (rom *ROM) PPUWrite(addr uint16, data uint8) bool {
-	var mappedAddr uint32 = 0
-	if rom.mapper.PPUMapWrite(addr, &mappedAddr) {
-		rom.chr[mappedAddr] = data
+	if rom.mapper.PPUWrite(addr, data) {
+		return true
+	}
+	if rom.vram != nil && addr >= 0x2000 && addr <= 0x3EFF {
+		rom.vram[addr&0x0FFF] = data
 		return true
 	}
 	return false
 }
 
 func (rom *ROM) PPURead(addr uint16, data *uint8) bool {
-	var mappedAddr uint32 = 0
-	if rom.mapper.PPUMapRead(addr, &mappedAddr) {
-		*data = rom.chr[mappedAddr]
+	if d, ok := rom.mapper.PPURead(addr); ok {
+		*data = d
+		return true
+	}
+	if rom.vram != nil && addr >= 0x2000 && addr <= 0x3EFF {
+		*data = rom.vram[addr&0x0FFF]
 		return true
 	}
 	return false
 }
 
+// Called once per CPU cycle
+func (rom *ROM) CPUClock() {
+	rom.mapper.CPUClock()
+}
+
+// Reports an address driven onto the PPU bus
+func (rom *ROM) PPUAddressSeen(addr uint16) {
+	rom.mapper.PPUAddressSeen(addr)
+}
+
+// Sample source for the board's expansion sound, nil when it has none
+func (rom *ROM) ExpansionAudio() func() float32 {
+	if a, ok := rom.mapper.(mapper.AudioMapper); ok {
+		return a.AudioOutput
+	}
+	return nil
+}
+
+func (rom *ROM) IRQ() bool {
+	return rom.mapper.IRQ()
+}
+
+// Writes the board state, including the four screen nametables
+func (rom *ROM) SaveState(w io.Writer) error {
+	if _, err := w.Write(rom.vram); err != nil {
+		return err
+	}
+	return rom.mapper.SaveState(w)
+}
+
+func (rom *ROM) LoadState(r io.Reader) error {
+	if _, err := io.ReadFull(r, rom.vram); err != nil {
+		return err
+	}
+	rom.ramDirty = true
+	return rom.mapper.LoadState(r)
+}
+
+func (rom *ROM) Info() Info {
+	return rom.info
+}
+
 func (rom *ROM) ImageValid() bool {
 	return rom.imageValid
 }
 
 func (rom *ROM) Reset() {
-	if rom.mapper != nil {
-		rom.mapper.Reset()
+	rom.mapper.Reset()
+	if rom.protection != nil {
+		rom.protection.reset()
 	}
 }
 
 func (rom *ROM) GetMirror() uint8 {
+	if mirror := rom.mapper.Mirroring(); mirror != mapper.MirrorHardware && rom.vram == nil {
+		return mirror
+	}
 	return rom.mirror
 }
@@ -0,0 +1,42 @@
+package cartridge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadBytesBadSize(t *testing.T) {
+	cases := []struct {
+		name      string
+		prgChunks uint8
+		chrChunks uint8
+		flags7    uint8
+		flags9    uint8
+	}{
+		{name: "no PRG ROM", prgChunks: 0x00, chrChunks: 0x01},
+		{name: "exponent overflowing an int", prgChunks: 0xFF, flags7: 0x08, flags9: 0x0F},
+		{name: "exponent asking for gigabytes", prgChunks: 35 << 2, flags7: 0x08, flags9: 0x0F},
+		{name: "CHR exponent asking for gigabytes", prgChunks: 0x01, chrChunks: 30 << 2, flags7: 0x08, flags9: 0xF0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := []byte{'N', 'E', 'S', 0x1A, tc.prgChunks, tc.chrChunks, 0x00, tc.flags7, 0x00, tc.flags9, 0, 0, 0, 0, 0, 0}
+			if _, err := LoadBytes(header); !errors.Is(err, ErrBadSize) {
+				t.Fatalf("err = %v, want %v", err, ErrBadSize)
+			}
+		})
+	}
+}
+
+func TestLoadBytesExponentSize(t *testing.T) {
+	// 2^14 * 3 bytes of PRG ROM, declared but missing from the file
+	header := []byte{'N', 'E', 'S', 0x1A, 14<<2 | 0x01, 0x00, 0x00, 0x08, 0x00, 0x0F, 0, 0, 0, 0, 0, 0}
+	_, err := LoadBytes(header)
+	var truncated *TruncatedError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("err = %v, want a TruncatedError", err)
+	}
+	if truncated.Want != 0xC000 {
+		t.Fatalf("PRG ROM size = %d, want %d", truncated.Want, 0xC000)
+	}
+}
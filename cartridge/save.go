@@ -0,0 +1,53 @@
+package cartridge
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Reports whether the cartridge keeps PRG-RAM across power cycles
+func (rom *ROM) Battery() bool {
+	return rom.info.Battery && rom.prgRAM != nil
+}
+
+// Reports whether PRG-RAM was written since the last save
+func (rom *ROM) SaveDirty() bool {
+	return rom.ramDirty
+}
+
+// Fills PRG-RAM from a save file, a short file only fills the start
+func (rom *ROM) LoadSave(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	copy(rom.prgRAM, data)
+	rom.ramDirty = false
+	return nil
+}
+
+// Writes PRG-RAM to a temporary file and renames it over path, so a crash
+// mid-write leaves the previous save intact
+func (rom *ROM) WriteSave(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(rom.prgRAM); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	rom.ramDirty = false
+	return nil
+}
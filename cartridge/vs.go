@@ -0,0 +1,91 @@
+package cartridge
+
+// Vs. System boards, from the high nibble of NES 2.0 byte 13
+const (
+	VsUnisystem uint8 = iota
+	VsRBIBaseball
+	VsTKOBoxing
+	VsSuperXevious
+	VsIceClimber
+	VsDualSystem
+	VsBungelingBay
+)
+
+// Sequences returned by the RBI Baseball and TKO Boxing protection chips
+// on successive reads of $5E01, $5E00 rewinds them
+var vsSecurity = map[uint8][32]uint8{
+	VsTKOBoxing: {
+		0xFF, 0xBF, 0xB7, 0x97, 0x97, 0x17, 0x57, 0x4F, 0x6F, 0x6B, 0xEB, 0xA9, 0xB1, 0x90, 0x94, 0x14,
+		0x56, 0x4E, 0x6F, 0x6B, 0xEB, 0xA9, 0xB1, 0x90, 0xD4, 0x5C, 0x3E, 0x26, 0x87, 0x83, 0x13, 0x00,
+	},
+	VsRBIBaseball: {
+		0x00, 0x00, 0x00, 0x00, 0xB4, 0x00, 0x00, 0x00, 0x00, 0x6F, 0x00, 0x00, 0x00, 0x00, 0x94, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	},
+}
+
+// Protection chip mapped into $5000-$5FFF on some Vs. boards
+type vsProtection struct {
+	hardware uint8
+	index    uint8
+	toggle   bool
+}
+
+// Reads with readOnly set leave the sequence where it is
+func (p *vsProtection) read(addr uint16, data *uint8, readOnly bool) bool {
+	switch p.hardware {
+	case VsRBIBaseball, VsTKOBoxing:
+		switch addr {
+		case 0x5E00:
+			if !readOnly {
+				p.index = 0
+			}
+			return true
+		case 0x5E01:
+			*data = vsSecurity[p.hardware][p.index&0x1F]
+			if !readOnly {
+				p.index++
+			}
+			return true
+		}
+	case VsSuperXevious:
+		switch addr {
+		case 0x54FF:
+			*data = 0x05
+			return true
+		case 0x5678:
+			*data = 0x01
+			if p.toggle {
+				*data = 0x00
+			}
+			return true
+		case 0x578F:
+			*data = 0x89
+			if p.toggle {
+				*data = 0xD1
+			}
+			return true
+		case 0x5567:
+			toggle := !p.toggle
+			if !readOnly {
+				p.toggle = toggle
+			}
+			*data = 0x3E
+			if toggle {
+				*data = 0x37
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func (p *vsProtection) reset() {
+	p.index = 0
+	p.toggle = false
+}
+
+// Reports whether the cartridge is a Vs. System arcade board
+func (rom *ROM) VsSystem() bool {
+	return rom.info.ConsoleType == ConsoleVsSystem
+}
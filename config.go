@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Hotkey names used in the config file
+const (
+	hotkeyPause       = "pause"
+	hotkeyStep        = "step"
+	hotkeyReset       = "reset"
+	hotkeyFastForward = "fast_forward"
+	hotkeyCoin1       = "coin1"
+	hotkeyCoin2       = "coin2"
+	hotkeyService     = "service"
+)
+
+// Bindings for one controller port, keyed by NES button name ("A", "Up", ...).
+// Keys are SDL key names, buttons SDL GameController button names and axes
+// a sign followed by an SDL GameController axis name, e.g. "-leftx"
+type PortConfig struct {
+	Keys    map[string]string `json:"keys"`
+	Buttons map[string]string `json:"buttons"`
+	Axes    map[string]string `json:"axes"`
+}
+
+type Config struct {
+	Ports   [2]PortConfig     `json:"ports"`
+	Hotkeys map[string]string `json:"hotkeys"`
+	// Vs. System DIP switches 1-8 from bit 0 up, ignored by other cartridges
+	DIPSwitches uint8 `json:"dip_switches"`
+}
+
+func defaultConfig() *Config {
+	pad := func() PortConfig {
+		return PortConfig{
+			Keys: map[string]string{},
+			Buttons: map[string]string{
+				"A":      "b",
+				"B":      "a",
+				"Select": "back",
+				"Start":  "start",
+				"Up":     "dpup",
+				"Down":   "dpdown",
+				"Left":   "dpleft",
+				"Right":  "dpright",
+			},
+			Axes: map[string]string{
+				"Up":    "-lefty",
+				"Down":  "+lefty",
+				"Left":  "-leftx",
+				"Right": "+leftx",
+			},
+		}
+	}
+	c := &Config{
+		Ports: [2]PortConfig{pad(), pad()},
+		Hotkeys: map[string]string{
+			hotkeyPause:       "Tab",
+			hotkeyStep:        "Space",
+			hotkeyReset:       "F1",
+			hotkeyFastForward: "`",
+			hotkeyCoin1:       "5",
+			hotkeyCoin2:       "6",
+			hotkeyService:     "9",
+		},
+	}
+	c.Ports[0].Keys = map[string]string{
+		"A":      "X",
+		"B":      "Z",
+		"Select": "Right Shift",
+		"Start":  "Return",
+		"Up":     "Up",
+		"Down":   "Down",
+		"Left":   "Left",
+		"Right":  "Right",
+	}
+	return c
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.json"
+	}
+	return filepath.Join(dir, "emuNES", "config.json")
+}
+
+// Reads the config at path, writing the defaults there if it does not exist
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		c := defaultConfig()
+		return c, c.save(path)
+	} else if err != nil {
+		return nil, err
+	}
+	c := defaultConfig()
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
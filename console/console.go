@@ -0,0 +1,110 @@
+package console
+
+import (
+	"image"
+	"io"
+
+	"github.com/laranc/emuNES/bus"
+	"github.com/laranc/emuNES/cartridge"
+	"github.com/laranc/emuNES/timing"
+)
+
+type Console struct {
+	bus    *bus.Bus
+	rom    *cartridge.ROM
+	frames uint64
+}
+
+func NewConsole() *Console {
+	return &Console{
+		bus:    bus.NewBus(),
+		rom:    nil,
+		frames: 0,
+	}
+}
+
+func (c *Console) InsertCartridge(rom *cartridge.ROM) {
+	c.rom = rom
+	c.bus.InsertCartridge(rom)
+}
+
+func (c *Console) ConnectController(port int, controller bus.Controller) {
+	c.bus.ConnectController(port, controller)
+}
+
+// Vs. System DIP switch bank, bit 0 is switch 1
+func (c *Console) SetDIPSwitches(dip uint8) {
+	c.bus.SetDIPSwitches(dip)
+}
+
+// Holds a Vs. System coin slot (0 or 1) switch closed while inserted is set
+func (c *Console) SetCoin(slot int, inserted bool) {
+	c.bus.SetCoin(slot, inserted)
+}
+
+func (c *Console) SetService(pressed bool) {
+	c.bus.SetService(pressed)
+}
+
+func (c *Console) SetRegion(region timing.Region) {
+	c.bus.SetRegion(region)
+}
+
+// Writes a line per executed instruction to w, nil disables tracing
+func (c *Console) SetTrace(w io.Writer) {
+	c.bus.CPUSetTrace(w)
+}
+
+func (c *Console) SetPC(pc uint16) {
+	c.bus.CPUSetPC(pc)
+}
+
+func (c *Console) Reset() {
+	c.bus.Reset()
+	c.frames = 0
+}
+
+func (c *Console) Clock() {
+	c.bus.Clock()
+}
+
+func (c *Console) Step() {
+	c.bus.Clock()
+	for !c.bus.CPUComplete() {
+		c.bus.Clock()
+	}
+	for c.bus.CPUComplete() {
+		c.bus.Clock()
+	}
+}
+
+func (c *Console) RunFrame() {
+	for !c.bus.PPUFrameComplete() {
+		c.bus.Clock()
+	}
+	c.frames++
+}
+
+func (c *Console) Frames() uint64 {
+	return c.frames
+}
+
+func (c *Console) Screen() *image.RGBA {
+	return c.bus.Screen()
+}
+
+func (c *Console) ReadSamples(buf []float32) int {
+	return c.bus.ReadSamples(buf)
+}
+
+func (c *Console) SetSampleRate(rate float64) {
+	c.bus.SetSampleRate(rate)
+}
+
+func (c *Console) Bus() *bus.Bus {
+	return c.bus
+}
+
+func (c *Console) ROM() *cartridge.ROM {
+	return c.rom
+}
@@ -0,0 +1,59 @@
+package controller
+
+const (
+	ButtonA uint8 = (1 << iota)
+	ButtonB
+	ButtonSelect
+	ButtonStart
+	ButtonUp
+	ButtonDown
+	ButtonLeft
+	ButtonRight
+)
+
+// Standard NES controller, a 4021 shift register latched while strobe is high
+type Joypad struct {
+	buttons uint8
+	shift   uint8
+	strobe  bool
+}
+
+func NewJoypad() *Joypad {
+	return &Joypad{
+		buttons: 0x00,
+		shift:   0x00,
+		strobe:  false,
+	}
+}
+
+func (j *Joypad) SetButton(button uint8, pressed bool) {
+	if pressed {
+		j.buttons |= button
+	} else {
+		j.buttons &= ^button
+	}
+}
+
+func (j *Joypad) Buttons() uint8 {
+	return j.buttons
+}
+
+// The register keeps reloading while strobe is high, so it holds the
+// buttons as they were when strobe falls
+func (j *Joypad) Write(data uint8) {
+	if j.strobe || data&0x01 != 0 {
+		j.shift = j.buttons
+	}
+	j.strobe = data&0x01 != 0
+}
+
+func (j *Joypad) Read() uint8 {
+	if j.strobe {
+		j.shift = j.buttons
+		return j.shift & 0x01
+	}
+	data := j.shift & 0x01
+	// Official controllers return 1 after all eight buttons are read
+	j.shift = (j.shift >> 1) | 0x80
+	return data
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/laranc/emuNES/cartridge"
+	"github.com/laranc/emuNES/mapper"
+	"github.com/laranc/emuNES/rp2C02"
+	"github.com/laranc/emuNES/timing"
+)
+
+type options struct {
+	rom      string
+	scale    int
+	region   string
+	debug    bool
+	headless bool
+	pc       int // -1 keeps the reset vector
+	trace    string
+	frames   uint64
+	config   string
+}
+
+func listMappers() {
+	for _, id := range mapper.Supported() {
+		fmt.Println(id)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] game.nes\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func parseFlags() options {
+	opts := options{pc: -1}
+	var pc string
+	var list bool
+	flag.Usage = usage
+	flag.IntVar(&opts.scale, "scale", rp2C02.Scale, "window scale factor")
+	flag.StringVar(&opts.region, "region", "auto", "console region: auto, ntsc, pal or dendy")
+	flag.BoolVar(&opts.debug, "debug", true, "show the debugger window")
+	flag.BoolVar(&opts.headless, "headless", false, "run without a window or audio device")
+	flag.StringVar(&pc, "pc", "", "start execution at this hex address instead of the reset vector")
+	flag.StringVar(&opts.trace, "trace", "", "write a CPU instruction trace to this file")
+	flag.Uint64Var(&opts.frames, "frames", 0, "exit after this many frames, 0 runs until closed")
+	flag.StringVar(&opts.config, "config", defaultConfigPath(), "bindings config file")
+	flag.BoolVar(&list, "list-mappers", false, "print the supported mapper numbers and exit")
+	flag.Parse()
+
+	if list {
+		listMappers()
+		os.Exit(0)
+	}
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+	opts.rom = flag.Arg(0)
+
+	if opts.region != "auto" {
+		if _, err := timing.ParseRegion(opts.region); err != nil {
+			fail(err)
+		}
+	}
+
+	if pc != "" {
+		pc = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(pc), "$"), "0x")
+		v, err := strconv.ParseUint(pc, 16, 16)
+		if err != nil {
+			fail(fmt.Errorf("invalid -pc %q", pc))
+		}
+		opts.pc = int(v)
+	}
+
+	if opts.scale < 1 {
+		fail(fmt.Errorf("invalid -scale %d", opts.scale))
+	}
+	return opts
+}
+
+// Region from the flag, or the cartridge header when set to auto
+func (opts options) regionFor(rom *cartridge.ROM) timing.Region {
+	if opts.region == "auto" {
+		return rom.Info().Region()
+	}
+	r, _ := timing.ParseRegion(opts.region)
+	return r
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "emuNES:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/laranc/emuNES/controller"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const axisThreshold = 16000
+
+var buttonNames = map[string]uint8{
+	"A":      controller.ButtonA,
+	"B":      controller.ButtonB,
+	"Select": controller.ButtonSelect,
+	"Start":  controller.ButtonStart,
+	"Up":     controller.ButtonUp,
+	"Down":   controller.ButtonDown,
+	"Left":   controller.ButtonLeft,
+	"Right":  controller.ButtonRight,
+}
+
+type keyBinding struct {
+	port   int
+	button uint8
+}
+
+type axisBinding struct {
+	axis     sdl.GameControllerAxis
+	positive bool
+	button   uint8
+}
+
+type input struct {
+	joypads [2]*controller.Joypad
+	pads    [2]*sdl.GameController
+	keys    map[sdl.Keycode]keyBinding
+	hotkeys map[sdl.Keycode]string
+	buttons [2]map[sdl.GameControllerButton]uint8
+	axes    [2][]axisBinding
+}
+
+func newInput(config *Config) *input {
+	in := &input{
+		joypads: [2]*controller.Joypad{controller.NewJoypad(), controller.NewJoypad()},
+		pads:    [2]*sdl.GameController{nil, nil},
+		keys:    map[sdl.Keycode]keyBinding{},
+		hotkeys: map[sdl.Keycode]string{},
+		buttons: [2]map[sdl.GameControllerButton]uint8{{}, {}},
+		axes:    [2][]axisBinding{nil, nil},
+	}
+	for port, pc := range config.Ports {
+		for name, key := range pc.Keys {
+			button, ok := buttonNames[name]
+			code := sdl.GetKeyFromName(key)
+			if !ok || code == sdl.K_UNKNOWN {
+				log.Printf("ignoring key binding %s=%q on port %d", name, key, port+1)
+				continue
+			}
+			in.keys[code] = keyBinding{port: port, button: button}
+		}
+		for name, b := range pc.Buttons {
+			button, ok := buttonNames[name]
+			gb := sdl.GameControllerGetButtonFromString(b)
+			if !ok || gb == sdl.CONTROLLER_BUTTON_INVALID {
+				log.Printf("ignoring button binding %s=%q on port %d", name, b, port+1)
+				continue
+			}
+			in.buttons[port][gb] = button
+		}
+		for name, a := range pc.Axes {
+			button, ok := buttonNames[name]
+			if !ok || len(a) < 2 || (a[0] != '+' && a[0] != '-') {
+				log.Printf("ignoring axis binding %s=%q on port %d", name, a, port+1)
+				continue
+			}
+			axis := sdl.GameControllerGetAxisFromString(strings.TrimLeft(a, "+-"))
+			if axis == sdl.CONTROLLER_AXIS_INVALID {
+				log.Printf("ignoring axis binding %s=%q on port %d", name, a, port+1)
+				continue
+			}
+			in.axes[port] = append(in.axes[port], axisBinding{axis: axis, positive: a[0] == '+', button: button})
+		}
+	}
+	for hotkey, key := range config.Hotkeys {
+		code := sdl.GetKeyFromName(key)
+		if code == sdl.K_UNKNOWN {
+			log.Printf("ignoring hotkey %s=%q", hotkey, key)
+			continue
+		}
+		in.hotkeys[code] = hotkey
+	}
+	return in
+}
+
+// Returns the hotkey bound to the key, if any, after updating the joypads
+func (in *input) handleKey(e sdl.KeyboardEvent) (string, bool) {
+	pressed := e.State == sdl.PRESSED
+	if b, ok := in.keys[e.Keysym.Sym]; ok {
+		in.joypads[b.port].SetButton(b.button, pressed)
+	}
+	hotkey, ok := in.hotkeys[e.Keysym.Sym]
+	return hotkey, ok
+}
+
+func (in *input) port(which sdl.JoystickID) int {
+	for i, pad := range in.pads {
+		if pad != nil && pad.Joystick().InstanceID() == which {
+			return i
+		}
+	}
+	return -1
+}
+
+func (in *input) handleButton(e sdl.ControllerButtonEvent) {
+	port := in.port(e.Which)
+	if port < 0 {
+		return
+	}
+	if button, ok := in.buttons[port][sdl.GameControllerButton(e.Button)]; ok {
+		in.joypads[port].SetButton(button, e.State == sdl.PRESSED)
+	}
+}
+
+func (in *input) handleAxis(e sdl.ControllerAxisEvent) {
+	port := in.port(e.Which)
+	if port < 0 {
+		return
+	}
+	for _, a := range in.axes[port] {
+		if a.axis != sdl.GameControllerAxis(e.Axis) {
+			continue
+		}
+		if a.positive {
+			in.joypads[port].SetButton(a.button, e.Value > axisThreshold)
+		} else {
+			in.joypads[port].SetButton(a.button, e.Value < -axisThreshold)
+		}
+	}
+}
+
+// Plugs a newly attached controller into the first free port
+func (in *input) handleDevice(e sdl.ControllerDeviceEvent) {
+	switch e.Type {
+	case sdl.CONTROLLERDEVICEADDED:
+		pad := sdl.GameControllerOpen(int(e.Which))
+		if pad == nil {
+			log.Println("opening controller failed:", sdl.GetError())
+			return
+		}
+		if in.port(pad.Joystick().InstanceID()) >= 0 {
+			// Already plugged in, SDL reference counts the handle
+			pad.Close()
+			return
+		}
+		for i := range in.pads {
+			if in.pads[i] == nil {
+				in.pads[i] = pad
+				log.Printf("%s connected to port %d", pad.Name(), i+1)
+				return
+			}
+		}
+		pad.Close()
+	case sdl.CONTROLLERDEVICEREMOVED:
+		if port := in.port(e.Which); port >= 0 {
+			log.Printf("%s disconnected from port %d", in.pads[port].Name(), port+1)
+			in.pads[port].Close()
+			in.pads[port] = nil
+			in.joypads[port].SetButton(0xFF, false)
+		}
+	}
+}
+
+func (in *input) close() {
+	for i, pad := range in.pads {
+		if pad != nil {
+			pad.Close()
+			in.pads[i] = nil
+		}
+	}
+}
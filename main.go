@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bufio"
+	_ "embed"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"sort"
+	"unsafe"
 
+	"github.com/laranc/emuNES/audio"
 	"github.com/laranc/emuNES/bus"
 	"github.com/laranc/emuNES/cartridge"
+	"github.com/laranc/emuNES/console"
 	"github.com/laranc/emuNES/mos6502"
 	"github.com/laranc/emuNES/rp2C02"
 	"github.com/veandco/go-sdl2/sdl"
@@ -15,22 +22,33 @@ import (
 
 // Constants
 const (
-	title  = "emuNES"
-	width  = 680
-	height = 480
-	scale  = 2
+	title             = "emuNES"
+	width             = 680
+	height            = 480
+	scale             = 2
+	fastForwardFrames = 4
 )
 
+//go:embed assets/nes.ttf
+var fontData []byte
+
 // Global State
 var (
-	debugWindow   *sdl.Window   = nil
-	gameWindow    *sdl.Window   = nil
-	debugRenderer *sdl.Renderer = nil
-	gameRenderer  *sdl.Renderer = nil
-	font          *ttf.Font     = nil
-	nes           *bus.Bus      = nil
-	stepMode      bool          = false
-	step          bool          = false
+	debugWindow   *sdl.Window      = nil
+	gameWindow    *sdl.Window      = nil
+	debugRenderer *sdl.Renderer    = nil
+	gameRenderer  *sdl.Renderer    = nil
+	gameTexture   *sdl.Texture     = nil
+	font          *ttf.Font        = nil
+	emu           *console.Console = nil
+	nes           *bus.Bus         = nil
+	sound         *audio.Stream    = nil
+	config        *Config          = nil
+	controls      *input           = nil
+	sram          *battery         = nil
+	fastForward   bool             = false
+	stepMode      bool             = false
+	step          bool             = false
 	asm           map[uint16]string
 	asmAddrs      []uint16
 )
@@ -46,127 +64,238 @@ var (
 )
 
 func main() {
-	err := sdl.Init(sdl.INIT_EVERYTHING)
+	opts := parseFlags()
+
+	emu = console.NewConsole()
+	nes = emu.Bus()
+	cart, err := cartridge.NewROM(opts.rom)
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
 	}
-	defer sdl.Quit()
+	emu.SetRegion(opts.regionFor(cart))
+	emu.InsertCartridge(cart)
+	sram = newBattery(cart, opts.rom)
+	defer sram.save()
 
-	err = ttf.Init()
-	if err != nil {
-		panic(err)
+	if opts.trace != "" {
+		f, err := os.Create(opts.trace)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w := bufio.NewWriter(f)
+		defer w.Flush()
+		emu.SetTrace(w)
 	}
-	defer ttf.Quit()
 
-	debugWindow, err = sdl.CreateWindow(title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width*scale, height*scale, sdl.WINDOW_SHOWN)
-	if err != nil {
-		panic(err)
+	if opts.headless {
+		sound = audio.NewStream(emu, audio.NewNullSink(sampleRate))
+		reset(opts)
+		for opts.frames == 0 || emu.Frames() < opts.frames {
+			emu.RunFrame()
+			sound.Update()
+			sram.update(emu.Frames())
+		}
+		return
 	}
-	defer debugWindow.Destroy()
 
-	gameWindow, err = sdl.CreateWindow("Game Title", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, rp2C02.ResX*rp2C02.Scale, rp2C02.ResY*rp2C02.Scale, sdl.WINDOW_SHOWN)
+	err = sdl.Init(sdl.INIT_EVERYTHING)
 	if err != nil {
 		panic(err)
 	}
-	defer gameWindow.Destroy()
+	defer sdl.Quit()
+
+	if opts.debug {
+		err = ttf.Init()
+		if err != nil {
+			panic(err)
+		}
+		defer ttf.Quit()
+
+		debugWindow, err = sdl.CreateWindow(title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, width*scale, height*scale, sdl.WINDOW_SHOWN)
+		if err != nil {
+			panic(err)
+		}
+		defer debugWindow.Destroy()
+
+		debugRenderer, err = sdl.CreateRenderer(debugWindow, -1, sdl.RENDERER_ACCELERATED)
+		if err != nil {
+			panic(err)
+		}
+		defer debugRenderer.Destroy()
+		debugRenderer.SetScale(scale, scale)
+
+		rw, err := sdl.RWFromMem(fontData)
+		if err != nil {
+			panic(err)
+		}
+		font, err = ttf.OpenFontRW(rw, 1, 8)
+		if err != nil {
+			panic(err)
+		}
+		defer font.Close()
+
+		asm = nes.Disassemble(0x0000, 0xFFFF)
+		asmAddrs = make([]uint16, 0, len(asm))
+		for k := range asm {
+			asmAddrs = append(asmAddrs, k)
+		}
+		sort.Slice(asmAddrs, func(i int, j int) bool {
+			return asmAddrs[i] < asmAddrs[j]
+		})
+	}
 
-	debugRenderer, err = sdl.CreateRenderer(debugWindow, -1, sdl.RENDERER_ACCELERATED)
+	gameWindow, err = sdl.CreateWindow(filepath.Base(opts.rom), sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, int32(rp2C02.ResX*opts.scale), int32(rp2C02.ResY*opts.scale), sdl.WINDOW_SHOWN)
 	if err != nil {
 		panic(err)
 	}
-	defer debugRenderer.Destroy()
-	debugRenderer.SetScale(scale, scale)
+	defer gameWindow.Destroy()
 
 	gameRenderer, err = sdl.CreateRenderer(gameWindow, -1, sdl.RENDERER_ACCELERATED)
 	if err != nil {
 		panic(err)
 	}
 	defer gameRenderer.Destroy()
-	gameRenderer.SetScale(rp2C02.Scale, rp2C02.Scale)
+	gameRenderer.SetScale(float32(opts.scale), float32(opts.scale))
 
-	font, err = ttf.OpenFont("./assets/nes.ttf", 8)
+	gameTexture, err = gameRenderer.CreateTexture(sdl.PIXELFORMAT_RGBA32, sdl.TEXTUREACCESS_STREAMING, rp2C02.ResX, rp2C02.ResY)
 	if err != nil {
 		panic(err)
 	}
-	defer font.Close()
+	defer gameTexture.Destroy()
 
-	nes = bus.NewBus()
-	nes.ConnectRenderer(gameRenderer)
-	cart := cartridge.NewROM("./nestest.nes")
-	if !cart.ImageValid() {
-		log.Fatal("reading from rom failed")
+	config, err = loadConfig(opts.config)
+	if err != nil {
+		log.Println("using default config:", err)
+		config = defaultConfig()
 	}
-	nes.InsertCartridge(cart)
-	asm = nes.Disassemble(0x0000, 0xFFFF)
-	asmAddrs = make([]uint16, 0, len(asm))
-	for k := range asm {
-		asmAddrs = append(asmAddrs, k)
+	controls = newInput(config)
+	defer controls.close()
+	emu.ConnectController(0, controls.joypads[0])
+	emu.ConnectController(1, controls.joypads[1])
+	emu.SetDIPSwitches(config.DIPSwitches)
+
+	var sink audio.Sink
+	sink, err = newSDLSink()
+	if err != nil {
+		log.Println("audio disabled:", err)
+		sink = audio.NewNullSink(sampleRate)
 	}
-	sort.Slice(asmAddrs, func(i int, j int) bool {
-		return asmAddrs[i] < asmAddrs[j]
-	})
-	nes.Reset()
-	run()
+	defer sink.Close()
+	sound = audio.NewStream(emu, sink)
+
+	reset(opts)
+	run(opts)
 }
 
-func run() {
-	go func() {
-		for {
-			if !stepMode {
-				nes.Clock()
-			} else {
-				if step {
-					nes.Clock()
-					step = false
-				}
-			}
-		}
+// Resets the console, then starts at -pc when it was given
+func reset(opts options) {
+	emu.Reset()
+	if opts.pc >= 0 {
+		emu.SetPC(uint16(opts.pc))
+	}
+}
 
-	}()
+func run(opts options) {
 	running := true
-	for running {
+	for running && (opts.frames == 0 || emu.Frames() < opts.frames) {
 		for e := sdl.PollEvent(); e != nil; e = sdl.PollEvent() {
 			switch t := e.(type) {
 			case sdl.QuitEvent:
 				running = false
 			case sdl.KeyboardEvent:
-				switch t.Keysym.Sym {
-				case sdl.K_TAB:
-					stepMode = !stepMode
-					if stepMode {
-						fmt.Println("Step by step mode on")
-					} else {
-						fmt.Println("Step by step mode off")
-					}
-				case sdl.K_SPACE:
-					step = true
-				default:
-					break
+				if hotkey, ok := controls.handleKey(t); ok && t.Repeat == 0 {
+					handleHotkey(opts, hotkey, t.State == sdl.PRESSED)
 				}
+			case sdl.ControllerButtonEvent:
+				controls.handleButton(t)
+			case sdl.ControllerAxisEvent:
+				controls.handleAxis(t)
+			case sdl.ControllerDeviceEvent:
+				controls.handleDevice(t)
 			}
 		}
 
-		debugRenderer.SetDrawColor(background.R, background.G, background.B, background.A)
-		debugRenderer.Clear()
-		drawRAM(2, 2, 0x0000, 16, 16)
-		drawRAM(2, 182, 0x8000, 16, 16)
-		drawCPU(448, 2)
-		drawCode(448, 72, 26)
-		drawPalettes()
-		//drawPatternTable(x, y)
-
-		gameRenderer.SetDrawColor(0, 0, 0, 255)
-		gameRenderer.Clear()
-		for !nes.PPUFrameComplete() {
-			nes.PPUClock()
+		if fastForward && !stepMode {
+			for range fastForwardFrames {
+				emu.RunFrame()
+			}
+		} else if !stepMode {
+			emu.RunFrame()
+		} else if step {
+			emu.Step()
+			step = false
 		}
-
-		debugRenderer.Present()
+		sram.update(emu.Frames())
+		drawScreen()
 		gameRenderer.Present()
-		sdl.Delay(16)
+
+		if opts.debug {
+			debugRenderer.SetDrawColor(background.R, background.G, background.B, background.A)
+			debugRenderer.Clear()
+			drawRAM(2, 2, 0x0000, 16, 16)
+			drawRAM(2, 182, 0x8000, 16, 16)
+			drawCPU(448, 2)
+			drawCode(448, 72, 26)
+			drawPalettes()
+			//drawPatternTable(x, y)
+			debugRenderer.Present()
+		}
+		// Audio paces the emulation by blocking while its buffer is full
+		if fastForward {
+			sound.Skip()
+		} else {
+			sound.Update()
+		}
+		if _, ok := sound.Sink().(*audio.NullSink); ok || stepMode {
+			sdl.Delay(16)
+		}
+	}
+}
+
+func handleHotkey(opts options, hotkey string, pressed bool) {
+	switch hotkey {
+	case hotkeyFastForward:
+		fastForward = pressed
+		return
+	case hotkeyCoin1:
+		emu.SetCoin(0, pressed)
+		return
+	case hotkeyCoin2:
+		emu.SetCoin(1, pressed)
+		return
+	case hotkeyService:
+		emu.SetService(pressed)
+		return
+	}
+	if !pressed {
+		return
+	}
+	switch hotkey {
+	case hotkeyPause:
+		stepMode = !stepMode
+		if stepMode {
+			fmt.Println("Step by step mode on")
+		} else {
+			fmt.Println("Step by step mode off")
+		}
+	case hotkeyStep:
+		step = true
+	case hotkeyReset:
+		reset(opts)
+	default:
+		break
 	}
 }
 
+func drawScreen() {
+	screen := emu.Screen()
+	gameTexture.Update(nil, unsafe.Pointer(&screen.Pix[0]), screen.Stride)
+	gameRenderer.SetDrawColor(0, 0, 0, 255)
+	gameRenderer.Clear()
+	gameRenderer.Copy(gameTexture, nil, nil)
+}
+
 func drawText(str string, x int32, y int32, color sdl.Color) {
 	text, err := font.RenderUTF8Blended(str, color)
 	if err != nil {
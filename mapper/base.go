@@ -0,0 +1,106 @@
+package mapper
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Memory shared by every board and the defaults of a mapper without IRQs,
+// mirroring control or PPU watching, embedded by each mapper
+type base struct {
+	prg    []uint8
+	chr    []uint8
+	ram    []uint8 // PRG-RAM, owned by the cartridge for battery saves
+	chrRAM bool
+}
+
+func makeBase(config Config) base {
+	return base{
+		prg:    config.PRG,
+		chr:    config.CHR,
+		ram:    config.PRGRAM,
+		chrRAM: config.CHRROMSize == 0,
+	}
+}
+
+// Offsets wrap around the memory, like the unconnected upper address lines
+// of a smaller chip
+func (b *base) readPRG(offset uint32) uint8 {
+	if len(b.prg) == 0 {
+		return 0x00
+	}
+	return b.prg[offset%uint32(len(b.prg))]
+}
+
+func (b *base) readCHR(offset uint32) uint8 {
+	if len(b.chr) == 0 {
+		return 0x00
+	}
+	return b.chr[offset%uint32(len(b.chr))]
+}
+
+func (b *base) writeCHR(offset uint32, data uint8) bool {
+	if !b.chrRAM || len(b.chr) == 0 {
+		return false
+	}
+	b.chr[offset%uint32(len(b.chr))] = data
+	return true
+}
+
+func (b *base) readRAM(offset uint32) (uint8, bool) {
+	if len(b.ram) == 0 {
+		return 0x00, false
+	}
+	return b.ram[offset%uint32(len(b.ram))], true
+}
+
+func (b *base) writeRAM(offset uint32, data uint8) bool {
+	if len(b.ram) == 0 {
+		return false
+	}
+	b.ram[offset%uint32(len(b.ram))] = data
+	return true
+}
+
+func (b *base) Mirroring() uint8 {
+	return MirrorHardware
+}
+
+func (b *base) IRQ() bool {
+	return false
+}
+
+func (b *base) CPUClock() {}
+
+func (b *base) PPUAddressSeen(addr uint16) {}
+
+// Writes the fixed size register fields followed by the RAM
+func (b *base) saveState(w io.Writer, fields ...any) error {
+	for _, f := range fields {
+		if err := binary.Write(w, binary.LittleEndian, f); err != nil {
+			return err
+		}
+	}
+	if b.chrRAM {
+		if _, err := w.Write(b.chr); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(b.ram)
+	return err
+}
+
+func (b *base) loadState(r io.Reader, fields ...any) error {
+	for _, f := range fields {
+		if err := binary.Read(r, binary.LittleEndian, f); err != nil {
+			return err
+		}
+	}
+	if b.chrRAM {
+		if _, err := io.ReadFull(r, b.chr); err != nil {
+			return err
+		}
+	}
+	_, err := io.ReadFull(r, b.ram)
+	return err
+}
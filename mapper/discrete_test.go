@@ -0,0 +1,297 @@
+package mapper
+
+import "testing"
+
+type write struct {
+	addr uint16
+	data uint8
+}
+
+type discreteCase struct {
+	name      string
+	id        uint16
+	submapper uint8
+	// ROM sizes and the bank sizes used to fill them, every byte of a bank
+	// holds its own bank number. A CHR size of 0 gives 8 KB of CHR-RAM
+	prgSize, prgBank int
+	chrSize, chrBank int
+	// PRG bytes to overwrite, so bus conflicts see a known value
+	patch  map[int]uint8
+	writes []write
+	cpu    map[uint16]uint8
+	ppu    map[uint16]uint8
+	mirror uint8
+}
+
+func testConfig(tc discreteCase) Config {
+	prg := make([]uint8, tc.prgSize)
+	for i := range prg {
+		prg[i] = uint8(i / tc.prgBank)
+	}
+	for offset, data := range tc.patch {
+		prg[offset] = data
+	}
+	chr := make([]uint8, tc.chrSize)
+	for i := range chr {
+		chr[i] = uint8(i / tc.chrBank)
+	}
+	if tc.chrSize == 0 {
+		chr = make([]uint8, 0x2000)
+	}
+	return Config{
+		ID:         tc.id,
+		Submapper:  tc.submapper,
+		PRGROMSize: tc.prgSize,
+		CHRROMSize: tc.chrSize,
+		PRGRAMSize: 0x2000,
+		CHRRAMSize: len(chr) - tc.chrSize,
+		PRG:        prg,
+		CHR:        chr,
+		PRGRAM:     make([]uint8, 0x2000),
+	}
+}
+
+var discreteCases = []discreteCase{
+	{
+		name: "11 selects PRG and CHR",
+		id:   11, prgSize: 0x20000, prgBank: 0x8000, chrSize: 0x20000, chrBank: 0x2000,
+		patch:  map[int]uint8{0x0000: 0xFF},
+		writes: []write{{0x8000, 0x32}},
+		cpu:    map[uint16]uint8{0x8000: 2, 0xFFFF: 2},
+		ppu:    map[uint16]uint8{0x0000: 3, 0x1FFF: 3},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "11 ANDs writes with ROM",
+		id:   11, prgSize: 0x20000, prgBank: 0x8000, chrSize: 0x20000, chrBank: 0x2000,
+		patch:  map[int]uint8{0x0000: 0x21},
+		writes: []write{{0x8000, 0x33}},
+		cpu:    map[uint16]uint8{0x8001: 1},
+		ppu:    map[uint16]uint8{0x0000: 2},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "34 submapper 1 is NINA-001",
+		id:   34, submapper: 1, prgSize: 0x10000, prgBank: 0x8000, chrSize: 0x10000, chrBank: 0x1000,
+		writes: []write{{0x7FFD, 0x01}, {0x7FFE, 0x05}, {0x7FFF, 0x09}, {0x8000, 0x00}},
+		cpu:    map[uint16]uint8{0x8000: 1, 0x7FFD: 0x01, 0x7FFE: 0x05, 0x7FFF: 0x09},
+		ppu:    map[uint16]uint8{0x0000: 5, 0x1000: 9},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "34 submapper 2 is BNROM",
+		id:   34, submapper: 2, prgSize: 0x20000, prgBank: 0x8000, chrSize: 0x2000, chrBank: 0x2000,
+		patch:  map[int]uint8{0x0000: 0xFF},
+		writes: []write{{0x7FFD, 0x01}, {0x8000, 0x03}},
+		cpu:    map[uint16]uint8{0x8000: 3, 0xFFFF: 3},
+		ppu:    map[uint16]uint8{0x0000: 0, 0x1000: 0},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "34 BNROM ANDs writes with ROM",
+		id:   34, submapper: 2, prgSize: 0x20000, prgBank: 0x8000, chrSize: 0x2000, chrBank: 0x2000,
+		patch:  map[int]uint8{0x0000: 0x02},
+		writes: []write{{0x8000, 0x03}},
+		cpu:    map[uint16]uint8{0x8000: 2},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "34 without submapper is NINA-001 with CHR-ROM over 8 KB",
+		id:   34, prgSize: 0x10000, prgBank: 0x8000, chrSize: 0x10000, chrBank: 0x1000,
+		writes: []write{{0x7FFD, 0x01}, {0x7FFE, 0x05}, {0x7FFF, 0x09}},
+		cpu:    map[uint16]uint8{0x8000: 1},
+		ppu:    map[uint16]uint8{0x0000: 5, 0x1000: 9},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "34 without submapper is BNROM with CHR-RAM",
+		id:   34, prgSize: 0x20000, prgBank: 0x8000,
+		patch:  map[int]uint8{0x0000: 0xFF},
+		writes: []write{{0x7FFD, 0x01}, {0x7FFE, 0x05}, {0x8000, 0x02}},
+		cpu:    map[uint16]uint8{0x8001: 2},
+		ppu:    map[uint16]uint8{0x0000: 0, 0x1000: 0},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "66 selects PRG and CHR",
+		id:   66, prgSize: 0x20000, prgBank: 0x8000, chrSize: 0x8000, chrBank: 0x2000,
+		patch:  map[int]uint8{0x0000: 0xFF},
+		writes: []write{{0x8000, 0x21}},
+		cpu:    map[uint16]uint8{0x8000: 2},
+		ppu:    map[uint16]uint8{0x0000: 1},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "66 ANDs writes with ROM",
+		id:   66, prgSize: 0x20000, prgBank: 0x8000, chrSize: 0x8000, chrBank: 0x2000,
+		patch:  map[int]uint8{0x0000: 0x12},
+		writes: []write{{0x8000, 0x33}},
+		cpu:    map[uint16]uint8{0x8001: 1},
+		ppu:    map[uint16]uint8{0x0000: 2},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "71 switches $8000 and fixes the last bank",
+		id:   71, prgSize: 0x20000, prgBank: 0x4000,
+		writes: []write{{0xC000, 0x03}, {0x9000, 0x10}},
+		cpu:    map[uint16]uint8{0x8000: 3, 0xBFFF: 3, 0xC000: 7, 0xFFFF: 7},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "71 Fire Hawk selects one-screen high",
+		id:   71, submapper: 1, prgSize: 0x20000, prgBank: 0x4000,
+		writes: []write{{0x9000, 0x10}},
+		cpu:    map[uint16]uint8{0x8000: 0},
+		mirror: MirrorOnescreenHigh,
+	},
+	{
+		name: "71 Fire Hawk selects one-screen low",
+		id:   71, submapper: 1, prgSize: 0x20000, prgBank: 0x4000,
+		writes: []write{{0x9000, 0x10}, {0x8000, 0x00}},
+		mirror: MirrorOnescreenLow,
+	},
+	{
+		name: "87 swaps the bank bits",
+		id:   87, prgSize: 0x8000, prgBank: 0x8000, chrSize: 0x8000, chrBank: 0x2000,
+		writes: []write{{0x6000, 0x01}},
+		ppu:    map[uint16]uint8{0x0000: 2, 0x1FFF: 2},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "87 bit 1 selects bank 1",
+		id:   87, prgSize: 0x8000, prgBank: 0x8000, chrSize: 0x8000, chrBank: 0x2000,
+		writes: []write{{0x7FFF, 0x02}},
+		ppu:    map[uint16]uint8{0x0000: 1},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "140 selects PRG and CHR",
+		id:   140, prgSize: 0x20000, prgBank: 0x8000, chrSize: 0x20000, chrBank: 0x2000,
+		writes: []write{{0x6000, 0x25}},
+		cpu:    map[uint16]uint8{0x8000: 2},
+		ppu:    map[uint16]uint8{0x0000: 5},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "180 switches $C000 and fixes the first bank",
+		id:   180, prgSize: 0x20000, prgBank: 0x4000,
+		patch:  map[int]uint8{0x0000: 0xFF},
+		writes: []write{{0xC000, 0x05}},
+		cpu:    map[uint16]uint8{0x8001: 0, 0xC000: 5, 0xFFFF: 5},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "180 ANDs writes with ROM",
+		id:   180, prgSize: 0x20000, prgBank: 0x4000,
+		patch:  map[int]uint8{0x0000: 0x03},
+		writes: []write{{0xC000, 0x06}},
+		cpu:    map[uint16]uint8{0xC001: 2},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "184 selects both CHR halves",
+		id:   184, prgSize: 0x8000, prgBank: 0x8000, chrSize: 0x8000, chrBank: 0x1000,
+		writes: []write{{0x6000, 0x53}},
+		ppu:    map[uint16]uint8{0x0000: 3, 0x0FFF: 3, 0x1000: 5, 0x1FFF: 5},
+		mirror: MirrorHardware,
+	},
+	{
+		name: "32 selects PRG and CHR",
+		id:   32, prgSize: 0x40000, prgBank: 0x2000, chrSize: 0x40000, chrBank: 0x0400,
+		writes: []write{{0x8000, 0x04}, {0xA000, 0x05}, {0xB000, 0x10}, {0xB007, 0x17}, {0x9000, 0x01}},
+		cpu:    map[uint16]uint8{0x8000: 4, 0xA000: 5, 0xC000: 30, 0xE000: 31},
+		ppu:    map[uint16]uint8{0x0000: 0x10, 0x1C00: 0x17},
+		mirror: MirrorHorizontal,
+	},
+	{
+		name: "32 swaps $8000 and $C000",
+		id:   32, prgSize: 0x40000, prgBank: 0x2000, chrSize: 0x40000, chrBank: 0x0400,
+		writes: []write{{0x8000, 0x04}, {0xA000, 0x05}, {0x9000, 0x02}},
+		cpu:    map[uint16]uint8{0x8000: 30, 0xA000: 5, 0xC000: 4, 0xE000: 31},
+		mirror: MirrorVertical,
+	},
+	{
+		name: "32 submapper 1 is one-screen without swapping",
+		id:   32, submapper: 1, prgSize: 0x40000, prgBank: 0x2000, chrSize: 0x40000, chrBank: 0x0400,
+		writes: []write{{0x8000, 0x04}, {0x9000, 0x03}},
+		cpu:    map[uint16]uint8{0x8000: 4, 0xC000: 30},
+		mirror: MirrorOnescreenLow,
+	},
+	{
+		name: "65 selects PRG and CHR",
+		id:   65, prgSize: 0x40000, prgBank: 0x2000, chrSize: 0x40000, chrBank: 0x0400,
+		writes: []write{{0x8000, 0x04}, {0xA000, 0x05}, {0xC000, 0x06}, {0xB002, 0x12}, {0x9001, 0x80}},
+		cpu:    map[uint16]uint8{0x8000: 4, 0xA000: 5, 0xC000: 6, 0xE000: 31},
+		ppu:    map[uint16]uint8{0x0800: 0x12},
+		mirror: MirrorHorizontal,
+	},
+}
+
+func TestDiscreteBanking(t *testing.T) {
+	for _, tc := range discreteCases {
+		t.Run(tc.name, func(t *testing.T) {
+			constructor, ok := Lookup(tc.id)
+			if !ok {
+				t.Fatalf("mapper %d is not registered", tc.id)
+			}
+			m, err := constructor(testConfig(tc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tc.writes {
+				m.CPUWrite(w.addr, w.data)
+			}
+			for addr, want := range tc.cpu {
+				if data, _ := m.CPURead(addr, false); data != want {
+					t.Errorf("CPU $%04X = %d, want %d", addr, data, want)
+				}
+			}
+			for addr, want := range tc.ppu {
+				if data, _ := m.PPURead(addr); data != want {
+					t.Errorf("PPU $%04X = %d, want %d", addr, data, want)
+				}
+			}
+			if mirror := m.Mirroring(); mirror != tc.mirror {
+				t.Errorf("mirroring = %d, want %d", mirror, tc.mirror)
+			}
+		})
+	}
+}
+
+func TestMapper065IRQ(t *testing.T) {
+	m := NewMapper065(testConfig(discreteCase{prgSize: 0x40000, prgBank: 0x2000, chrSize: 0x2000, chrBank: 0x0400}))
+	m.CPUWrite(0x9005, 0x00)
+	m.CPUWrite(0x9006, 0x03)
+	m.CPUWrite(0x9004, 0x00)
+	m.CPUClock()
+	if m.IRQ() {
+		t.Fatal("IRQ raised while disabled")
+	}
+	m.CPUWrite(0x9003, 0x80)
+	for i := 0; i < 2; i++ {
+		m.CPUClock()
+		if m.IRQ() {
+			t.Fatalf("IRQ raised after %d cycles", i+1)
+		}
+	}
+	m.CPUClock()
+	if !m.IRQ() {
+		t.Fatal("IRQ not raised when the counter reached zero")
+	}
+	m.CPUClock()
+	m.CPUWrite(0x9003, 0x80)
+	if m.IRQ() {
+		t.Fatal("IRQ not acknowledged by $9003")
+	}
+	m.CPUClock()
+	if m.IRQ() {
+		t.Fatal("counter kept running past zero")
+	}
+	m.CPUWrite(0x9004, 0x00)
+	m.CPUClock()
+	m.CPUClock()
+	m.CPUClock()
+	if !m.IRQ() {
+		t.Fatal("IRQ not raised after reloading from $9004")
+	}
+}
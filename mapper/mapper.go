@@ -1,9 +1,40 @@
 package mapper
 
+import "io"
+
+const (
+	MirrorHorizontal uint8 = iota
+	MirrorVertical
+	MirrorOnescreenLow
+	MirrorOnescreenHigh
+	MirrorFourScreen
+	MirrorHardware // Fixed by the board, see the cartridge header
+)
+
+// Cartridge board logic. Reads and writes return false for addresses the
+// board leaves unmapped, so the bus or PPU can serve them instead. CPU reads
+// with readOnly set come from debuggers and must not change any state
 type Mapper interface {
-	CPUMapRead(addr uint16, mappedAddr *uint32) bool
-	CPUMapWrite(addr uint16, mappedAddr *uint32) bool
-	PPUMapRead(addr uint16, mappedAddr *uint32) bool
-	PPUMapWrite(addr uint16, mappedAddr *uint32) bool
+	CPURead(addr uint16, readOnly bool) (uint8, bool)
+	CPUWrite(addr uint16, data uint8) bool
+	PPURead(addr uint16) (uint8, bool)
+	PPUWrite(addr uint16, data uint8) bool
+	// Current nametable layout, MirrorHardware defers to the header
+	Mirroring() uint8
+	IRQ() bool
+	// Called once per CPU cycle
+	CPUClock()
+	// Called for every PPU fetch and for $2006/$2007 accesses, so boards can
+	// watch A12 or the tiles being drawn
+	PPUAddressSeen(addr uint16)
+	// Registers, CHR-RAM and PRG-RAM
+	SaveState(w io.Writer) error
+	LoadState(r io.Reader) error
 	Reset()
 }
+
+// Implemented by boards with expansion sound, mixed in by the APU
+type AudioMapper interface {
+	// Current level on the same scale as the APU mix, roughly 0 to 1
+	AudioOutput() float32
+}
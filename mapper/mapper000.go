@@ -1,55 +1,62 @@
 package mapper
 
+import "io"
+
+// NROM, 16 or 32 KB of PRG and 8 KB of CHR without banking
 type Mapper000 struct {
-	prgBanks uint8
-	chrBanks uint8
+	base
+}
+
+func init() {
+	Register(0, func(config Config) (Mapper, error) {
+		return NewMapper000(config), nil
+	})
 }
 
-func MakeMapper000(prgBanks uint8, chrBanks uint8) Mapper000 {
-	return Mapper000{
-		prgBanks: prgBanks,
-		chrBanks: chrBanks,
+func NewMapper000(config Config) *Mapper000 {
+	return &Mapper000{
+		base: makeBase(config),
 	}
 }
 
-func (m Mapper000) CPUMapRead(addr uint16, mappedAddr *uint32) bool {
-	if addr >= 0x8000 && addr <= 0xFFFF {
-		a := uint16(0x3FFF)
-		if m.prgBanks > 1 {
-			a = 0x7FFF
-		}
-		*mappedAddr = uint32(addr & a)
-		return true
+func (m *Mapper000) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		// A 16 KB image is mirrored into $C000-$FFFF
+		return m.readPRG(uint32(addr & 0x7FFF)), true
 	}
-	return false
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
 }
 
-func (m Mapper000) CPUMapWrite(addr uint16, mappedAddr *uint32) bool {
-	if addr >= 0x8000 && addr <= 0xFFFF {
-		a := uint16(0x3FFF)
-		if m.prgBanks > 1 {
-			a = 0x7FFF
-		}
-		*mappedAddr = uint32(addr & a)
-		return true
+func (m *Mapper000) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
 	}
 	return false
 }
 
-func (m Mapper000) PPUMapRead(addr uint16, mappedAddr *uint32) bool {
+func (m *Mapper000) PPURead(addr uint16) (uint8, bool) {
 	if addr <= 0x1FFF {
-		*mappedAddr = uint32(addr)
-		return true
+		return m.readCHR(uint32(addr)), true
 	}
-	return false
+	return 0x00, false
 }
 
-func (m Mapper000) PPUMapWrite(addr uint16, mappedAddr *uint32) bool {
-	if addr <= 0x1FFF && m.chrBanks == 0 {
-		*mappedAddr = uint32(addr)
-		return true
+func (m *Mapper000) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(addr), data)
 	}
 	return false
 }
 
-func (m Mapper000) Reset() {}
+func (m *Mapper000) SaveState(w io.Writer) error {
+	return m.saveState(w)
+}
+
+func (m *Mapper000) LoadState(r io.Reader) error {
+	return m.loadState(r)
+}
+
+func (m *Mapper000) Reset() {}
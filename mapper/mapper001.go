@@ -0,0 +1,194 @@
+package mapper
+
+import "io"
+
+// MMC1 (SxROM). Registers are loaded one bit at a time through a 5-bit
+// shift register written at $8000-$FFFF, address bits 13-14 of the fifth
+// write select the register
+type Mapper001 struct {
+	base
+	shift      uint8
+	count      uint8
+	control    uint8
+	chrBank0   uint8
+	chrBank1   uint8
+	prgBank    uint8
+	idleCycles uint64 // CPU cycles since the last serial write
+}
+
+func init() {
+	Register(1, func(config Config) (Mapper, error) {
+		return NewMapper001(config), nil
+	})
+}
+
+func NewMapper001(config Config) *Mapper001 {
+	m := &Mapper001{
+		base: makeBase(config),
+	}
+	m.Reset()
+	return m
+}
+
+// SUROM and SXROM use CHR bank bit 4 to select a 256 KB half of PRG ROM
+func (m *Mapper001) prgOuter() uint32 {
+	if len(m.prg) > 0x40000 {
+		return uint32(m.chrBank0&0x10) << 14
+	}
+	return 0
+}
+
+func (m *Mapper001) prgOffset(addr uint16) uint32 {
+	bank := uint32(m.prgBank & 0x0F)
+	last := uint32(min(len(m.prg), 0x40000)/0x4000) - 1
+	var offset uint32
+	switch (m.control >> 2) & 0x03 {
+	case 0, 1:
+		// 32 KB, low bit of the bank number ignored
+		offset = (bank&0x0E)*0x4000 + uint32(addr&0x7FFF)
+	case 2:
+		// First bank fixed at $8000
+		if addr < 0xC000 {
+			offset = uint32(addr & 0x3FFF)
+		} else {
+			offset = bank*0x4000 + uint32(addr&0x3FFF)
+		}
+	case 3:
+		// Last bank fixed at $C000
+		if addr < 0xC000 {
+			offset = bank*0x4000 + uint32(addr&0x3FFF)
+		} else {
+			offset = last*0x4000 + uint32(addr&0x3FFF)
+		}
+	}
+	return m.prgOuter() + offset
+}
+
+// SOROM banks 16 KB of PRG-RAM with CHR bank bit 3, SXROM 32 KB with bits
+// 2-3, bit 4 of the PRG register disables the RAM
+func (m *Mapper001) ramOffset(addr uint16) (uint32, bool) {
+	if m.prgBank&0x10 != 0 {
+		return 0, false
+	}
+	var bank uint32
+	switch {
+	case len(m.ram) > 0x4000:
+		bank = uint32(m.chrBank0>>2) & 0x03
+	case len(m.ram) > 0x2000:
+		bank = uint32(m.chrBank0>>3) & 0x01
+	}
+	return bank*0x2000 + uint32(addr&0x1FFF), true
+}
+
+func (m *Mapper001) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(m.prgOffset(addr)), true
+	}
+	if addr >= 0x6000 {
+		if offset, ok := m.ramOffset(addr); ok {
+			return m.readRAM(offset)
+		}
+	}
+	return 0x00, false
+}
+
+func (m *Mapper001) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		if offset, ok := m.ramOffset(addr); ok {
+			return m.writeRAM(offset, data)
+		}
+		return false
+	}
+	if addr < 0x8000 {
+		return false
+	}
+	// Writes on consecutive cycles, such as the dummy write of a
+	// read-modify-write instruction, are ignored after the first
+	ignore := m.idleCycles < 2
+	m.idleCycles = 0
+	if ignore {
+		return true
+	}
+	if data&0x80 != 0 {
+		m.shift = 0x00
+		m.count = 0
+		m.control |= 0x0C
+		return true
+	}
+	m.shift |= (data & 0x01) << m.count
+	m.count++
+	if m.count == 5 {
+		switch (addr >> 13) & 0x03 {
+		case 0:
+			m.control = m.shift
+		case 1:
+			m.chrBank0 = m.shift
+		case 2:
+			m.chrBank1 = m.shift
+		case 3:
+			m.prgBank = m.shift
+		}
+		m.shift = 0x00
+		m.count = 0
+	}
+	return true
+}
+
+func (m *Mapper001) chrOffset(addr uint16) uint32 {
+	if m.control&0x10 == 0 {
+		// 8 KB, low bit of the bank number ignored
+		return uint32(m.chrBank0&0x1E)*0x1000 + uint32(addr&0x1FFF)
+	} else if addr < 0x1000 {
+		return uint32(m.chrBank0)*0x1000 + uint32(addr&0x0FFF)
+	}
+	return uint32(m.chrBank1)*0x1000 + uint32(addr&0x0FFF)
+}
+
+func (m *Mapper001) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper001) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper001) Mirroring() uint8 {
+	switch m.control & 0x03 {
+	case 0:
+		return MirrorOnescreenLow
+	case 1:
+		return MirrorOnescreenHigh
+	case 2:
+		return MirrorVertical
+	default:
+		return MirrorHorizontal
+	}
+}
+
+func (m *Mapper001) CPUClock() {
+	m.idleCycles++
+}
+
+func (m *Mapper001) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.shift, &m.count, &m.control, &m.chrBank0, &m.chrBank1, &m.prgBank, &m.idleCycles)
+}
+
+func (m *Mapper001) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.shift, &m.count, &m.control, &m.chrBank0, &m.chrBank1, &m.prgBank, &m.idleCycles)
+}
+
+func (m *Mapper001) Reset() {
+	m.shift = 0x00
+	m.count = 0
+	m.control = 0x0C
+	m.chrBank0 = 0x00
+	m.chrBank1 = 0x00
+	m.prgBank = 0x00
+	m.idleCycles = 2
+}
@@ -0,0 +1,81 @@
+package mapper
+
+import "io"
+
+// UxROM, switchable 16 KB at $8000 and the last bank fixed at $C000
+type Mapper002 struct {
+	base
+	conflicts bool
+	bank      uint8
+}
+
+func init() {
+	Register(2, func(config Config) (Mapper, error) {
+		if config.Submapper > 2 {
+			return nil, ErrSubmapper
+		}
+		return NewMapper002(config, config.Submapper == 2), nil
+	})
+}
+
+func NewMapper002(config Config, conflicts bool) *Mapper002 {
+	return &Mapper002{
+		base:      makeBase(config),
+		conflicts: conflicts,
+		bank:      0,
+	}
+}
+
+func (m *Mapper002) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 && addr <= 0xBFFF {
+		return m.readPRG(uint32(m.bank)*0x4000 + uint32(addr&0x3FFF)), true
+	}
+	if addr >= 0xC000 {
+		return m.readPRG(uint32(len(m.prg)-0x4000) + uint32(addr&0x3FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper002) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x8000 {
+		if m.conflicts {
+			rom, _ := m.CPURead(addr, true)
+			data &= rom
+		}
+		m.bank = data
+		return true
+	}
+	if addr >= 0x6000 {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper002) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper002) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper002) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper002) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper002) Reset() {
+	m.bank = 0
+}
@@ -0,0 +1,78 @@
+package mapper
+
+import "io"
+
+// CNROM, fixed PRG as on NROM and a switchable 8 KB CHR bank
+type Mapper003 struct {
+	base
+	conflicts bool
+	bank      uint8
+}
+
+func init() {
+	Register(3, func(config Config) (Mapper, error) {
+		if config.Submapper > 2 {
+			return nil, ErrSubmapper
+		}
+		return NewMapper003(config, config.Submapper == 2), nil
+	})
+}
+
+func NewMapper003(config Config, conflicts bool) *Mapper003 {
+	return &Mapper003{
+		base:      makeBase(config),
+		conflicts: conflicts,
+		bank:      0,
+	}
+}
+
+func (m *Mapper003) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(addr & 0x7FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper003) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x8000 {
+		if m.conflicts {
+			rom, _ := m.CPURead(addr, true)
+			data &= rom
+		}
+		m.bank = data
+		return true
+	}
+	if addr >= 0x6000 {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper003) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(m.bank)*0x2000 + uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper003) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper003) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper003) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper003) Reset() {
+	m.bank = 0
+}
@@ -0,0 +1,204 @@
+package mapper
+
+import "io"
+
+// MMC3 (TxROM). Eight bank registers are selected through $8000 and loaded
+// through $8001, the scanline counter is clocked by rising edges of PPU A12
+type Mapper004 struct {
+	base
+	oldIRQ     bool // MMC3A and Sharp chips only raise IRQ on a decrement to 0
+	bankSelect uint8
+	registers  [8]uint8
+	mirror     uint8
+	ramProtect uint8
+	irqLatch   uint8
+	irqCounter uint8
+	irqReload  bool
+	irqEnable  bool
+	irqActive  bool
+	a12        bool
+	a12Low     uint64 // CPU cycles A12 has been low
+}
+
+func init() {
+	Register(4, func(config Config) (Mapper, error) {
+		// MMC6 (1) and the MC-ACC (3) behave differently, 2 is deprecated
+		if config.Submapper != 0 && config.Submapper != 4 {
+			return nil, ErrSubmapper
+		}
+		return NewMapper004(config), nil
+	})
+}
+
+func NewMapper004(config Config) *Mapper004 {
+	m := &Mapper004{
+		base:   makeBase(config),
+		oldIRQ: config.Submapper == 4,
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper004) prgOffset(addr uint16) uint32 {
+	var bank uint32
+	banks := uint32(len(m.prg) / 0x2000)
+	switch (addr >> 13) & 0x03 {
+	case 0:
+		bank = uint32(m.registers[6])
+		if m.bankSelect&0x40 != 0 {
+			bank = banks - 2
+		}
+	case 1:
+		bank = uint32(m.registers[7])
+	case 2:
+		bank = banks - 2
+		if m.bankSelect&0x40 != 0 {
+			bank = uint32(m.registers[6])
+		}
+	case 3:
+		bank = banks - 1
+	}
+	return bank*0x2000 + uint32(addr&0x1FFF)
+}
+
+// Bit 7 of $A001 enables the RAM, bit 6 protects it from writes
+func (m *Mapper004) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(m.prgOffset(addr)), true
+	}
+	if addr >= 0x6000 && m.ramProtect&0x80 != 0 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper004) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		if m.ramProtect&0xC0 != 0x80 {
+			return false
+		}
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	if addr < 0x8000 {
+		return false
+	}
+	even := addr&0x0001 == 0
+	switch {
+	case addr <= 0x9FFF && even:
+		m.bankSelect = data
+	case addr <= 0x9FFF:
+		m.registers[m.bankSelect&0x07] = data
+	case addr <= 0xBFFF && even:
+		m.mirror = MirrorVertical
+		if data&0x01 != 0 {
+			m.mirror = MirrorHorizontal
+		}
+	case addr <= 0xBFFF:
+		m.ramProtect = data
+	case addr <= 0xDFFF && even:
+		m.irqLatch = data
+	case addr <= 0xDFFF:
+		m.irqCounter = 0
+		m.irqReload = true
+	case even:
+		m.irqEnable = false
+		m.irqActive = false
+	default:
+		m.irqEnable = true
+	}
+	return true
+}
+
+func (m *Mapper004) chrOffset(addr uint16) uint32 {
+	if m.bankSelect&0x80 != 0 {
+		addr ^= 0x1000
+	}
+	var bank uint32
+	switch {
+	case addr < 0x0800:
+		bank = uint32(m.registers[0]&0xFE) + uint32(addr>>10&0x01)
+	case addr < 0x1000:
+		bank = uint32(m.registers[1]&0xFE) + uint32(addr>>10&0x01)
+	default:
+		bank = uint32(m.registers[2+((addr-0x1000)>>10)])
+	}
+	return bank*0x0400 + uint32(addr&0x03FF)
+}
+
+func (m *Mapper004) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper004) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper004) Mirroring() uint8 {
+	return m.mirror
+}
+
+// A12 has to stay low for a few CPU cycles before a rise counts, which
+// filters out the toggling between sprite pattern fetches
+func (m *Mapper004) PPUAddressSeen(addr uint16) {
+	a12 := addr&0x1000 != 0
+	if a12 && !m.a12 && m.a12Low >= 3 {
+		m.clockCounter()
+	}
+	if !a12 && m.a12 {
+		m.a12Low = 0
+	}
+	m.a12 = a12
+}
+
+func (m *Mapper004) clockCounter() {
+	zero := m.irqCounter == 0
+	if zero || m.irqReload {
+		m.irqCounter = m.irqLatch
+	} else {
+		m.irqCounter--
+	}
+	if m.irqCounter == 0 && m.irqEnable && (!m.oldIRQ || !zero || m.irqReload) {
+		m.irqActive = true
+	}
+	m.irqReload = false
+}
+
+func (m *Mapper004) CPUClock() {
+	if !m.a12 {
+		m.a12Low++
+	}
+}
+
+func (m *Mapper004) IRQ() bool {
+	return m.irqActive
+}
+
+func (m *Mapper004) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bankSelect, &m.registers, &m.mirror, &m.ramProtect, &m.irqLatch, &m.irqCounter,
+		&m.irqReload, &m.irqEnable, &m.irqActive, &m.a12, &m.a12Low)
+}
+
+func (m *Mapper004) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bankSelect, &m.registers, &m.mirror, &m.ramProtect, &m.irqLatch, &m.irqCounter,
+		&m.irqReload, &m.irqEnable, &m.irqActive, &m.a12, &m.a12Low)
+}
+
+func (m *Mapper004) Reset() {
+	m.bankSelect = 0x00
+	m.registers = [8]uint8{0, 2, 4, 5, 6, 7, 0, 1}
+	m.mirror = MirrorHardware
+	m.ramProtect = 0x80
+	m.irqLatch = 0x00
+	m.irqCounter = 0x00
+	m.irqReload = false
+	m.irqEnable = false
+	m.irqActive = false
+	m.a12 = false
+	m.a12Low = 0
+}
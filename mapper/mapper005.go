@@ -0,0 +1,421 @@
+package mapper
+
+import "io"
+
+// MMC5 (ExROM). Four PRG and four CHR banking modes, a second CHR bank set
+// for 8x16 sprite backgrounds, 1 KB of ExRAM usable as a nametable,
+// extended attributes or plain RAM, a vertical split, a fill-mode nametable,
+// a scanline IRQ and an 8x8 multiplier. The chip has no view of the PPU
+// other than its bus, so scanlines are found by watching fetches and the
+// sprite size by snooping $2000. Fetches stopping for a few CPU cycles end
+// the frame, which covers rendering being turned off too
+type Mapper005 struct {
+	base
+	prgMode     uint8
+	chrMode     uint8
+	ramProtect  [2]uint8
+	exramMode   uint8
+	ntMapping   uint8 // $5105, two bits per nametable
+	fillTile    uint8
+	fillAttr    uint8
+	prgBank     [5]uint8   // $5113-$5117, $5113 maps PRG-RAM at $6000
+	chrBank     [12]uint16 // $5120-$5127 sprite set, $5128-$512B background set
+	chrUpper    uint8
+	lastSetB    bool // Whether $5128-$512B were written last
+	splitCtrl   uint8
+	splitScroll uint8
+	splitBank   uint8
+	irqCompare  uint8
+	irqEnable   bool
+	irqPending  bool
+	multiplier  [2]uint8
+	ppuCtrl     uint8
+	exram       [1024]uint8
+	ciram       [2048]uint8 // The PPU only mirrors in fixed layouts, so the board keeps the nametables
+	inFrame     bool
+	scanline    uint8
+	idle        int32 // CPU cycles since the last PPU read
+	fetching    bool
+	lastAddr    uint16
+	matches     uint8 // Consecutive reads of lastAddr
+	tile        uint8 // Background tile being fetched, 0-1 are prefetched for the next line
+	spriteFetch bool
+	exAttr      uint8 // ExRAM byte of the tile being fetched
+	split       bool
+	splitY      uint16
+}
+
+func init() {
+	Register(5, func(config Config) (Mapper, error) {
+		return NewMapper005(config), nil
+	})
+}
+
+func NewMapper005(config Config) *Mapper005 {
+	m := &Mapper005{
+		base: makeBase(config),
+	}
+	m.Reset()
+	return m
+}
+
+// Offset of addr in PRG-ROM, or in PRG-RAM when the bank selects it
+func (m *Mapper005) prgOffset(addr uint16) (uint32, bool) {
+	var reg int
+	var size uint32
+	switch m.prgMode {
+	case 0:
+		reg, size = 4, 0x8000
+	case 1:
+		reg, size = 2+2*int((addr>>14)&0x01), 0x4000
+	case 2:
+		if addr < 0xC000 {
+			reg, size = 2, 0x4000
+		} else {
+			reg, size = 3+int((addr>>13)&0x01), 0x2000
+		}
+	default:
+		reg, size = 1+int((addr-0x8000)>>13), 0x2000
+	}
+	bank := m.prgBank[reg]
+	rom := reg == 4 || bank&0x80 != 0
+	offset := uint32(bank&0x7F)*0x2000&^(size-1) + uint32(addr)&(size-1)
+	return offset, rom
+}
+
+func (m *Mapper005) ramWritable() bool {
+	return m.ramProtect[0] == 0x02 && m.ramProtect[1] == 0x01
+}
+
+func (m *Mapper005) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	switch {
+	case addr >= 0x8000:
+		// Fetching the NMI vector ends the frame
+		if !readOnly && (addr == 0xFFFA || addr == 0xFFFB) {
+			m.inFrame = false
+			m.scanline = 0
+			m.irqPending = false
+		}
+		offset, rom := m.prgOffset(addr)
+		if rom {
+			return m.readPRG(offset), true
+		}
+		return m.readRAM(offset)
+	case addr >= 0x6000:
+		return m.readRAM(uint32(m.prgBank[0]&0x07)*0x2000 + uint32(addr&0x1FFF))
+	case addr >= 0x5C00:
+		if m.exramMode >= 2 {
+			return m.exram[addr&0x03FF], true
+		}
+	case addr == 0x5204:
+		var data uint8
+		if m.irqPending {
+			data |= 0x80
+		}
+		if m.inFrame {
+			data |= 0x40
+		}
+		if !readOnly {
+			m.irqPending = false
+		}
+		return data, true
+	case addr == 0x5205:
+		return uint8(uint16(m.multiplier[0]) * uint16(m.multiplier[1])), true
+	case addr == 0x5206:
+		return uint8(uint16(m.multiplier[0]) * uint16(m.multiplier[1]) >> 8), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper005) CPUWrite(addr uint16, data uint8) bool {
+	switch {
+	case addr >= 0x8000:
+		if offset, rom := m.prgOffset(addr); !rom && m.ramWritable() {
+			return m.writeRAM(offset, data)
+		}
+		return true
+	case addr >= 0x6000:
+		if !m.ramWritable() {
+			return false
+		}
+		return m.writeRAM(uint32(m.prgBank[0]&0x07)*0x2000+uint32(addr&0x1FFF), data)
+	case addr >= 0x5C00:
+		switch m.exramMode {
+		case 0, 1:
+			// Only writable while rendering, zeros land otherwise
+			if !m.inFrame {
+				data = 0x00
+			}
+			m.exram[addr&0x03FF] = data
+		case 2:
+			m.exram[addr&0x03FF] = data
+		}
+		return true
+	case addr >= 0x2000 && addr <= 0x3FFF:
+		// Seen on the way to the PPU, which still handles the write
+		if addr&0x2007 == 0x2000 {
+			m.ppuCtrl = data
+		}
+		return false
+	}
+	switch {
+	case addr == 0x5100:
+		m.prgMode = data & 0x03
+	case addr == 0x5101:
+		m.chrMode = data & 0x03
+	case addr == 0x5102 || addr == 0x5103:
+		m.ramProtect[addr-0x5102] = data & 0x03
+	case addr == 0x5104:
+		m.exramMode = data & 0x03
+	case addr == 0x5105:
+		m.ntMapping = data
+	case addr == 0x5106:
+		m.fillTile = data
+	case addr == 0x5107:
+		m.fillAttr = data & 0x03
+	case addr >= 0x5113 && addr <= 0x5117:
+		m.prgBank[addr-0x5113] = data
+	case addr >= 0x5120 && addr <= 0x512B:
+		m.chrBank[addr-0x5120] = uint16(m.chrUpper)<<8 | uint16(data)
+		m.lastSetB = addr >= 0x5128
+	case addr == 0x5130:
+		m.chrUpper = data & 0x03
+	case addr == 0x5200:
+		m.splitCtrl = data
+	case addr == 0x5201:
+		m.splitScroll = data
+	case addr == 0x5202:
+		m.splitBank = data
+	case addr == 0x5203:
+		m.irqCompare = data
+	case addr == 0x5204:
+		m.irqEnable = data&0x80 != 0
+	case addr == 0x5205 || addr == 0x5206:
+		m.multiplier[addr-0x5205] = data
+	default:
+		return false
+	}
+	return true
+}
+
+// Register of the selected set covering addr, the background set only spans
+// $0000-$0FFF and repeats in $1000-$1FFF
+func (m *Mapper005) chrOffset(addr uint16, setB bool) uint32 {
+	size := uint16(0x2000) >> m.chrMode
+	reg := (addr/size+1)*(8>>m.chrMode) - 1
+	if setB {
+		reg = 8 + reg&0x03
+	}
+	return uint32(m.chrBank[reg])*uint32(size) + uint32(addr%size)
+}
+
+// In 8x16 mode sprites and background use their own sets while rendering,
+// otherwise the last set written applies to everything
+func (m *Mapper005) useSetB() bool {
+	if m.ppuCtrl&0x20 != 0 && m.fetching {
+		return !m.spriteFetch
+	}
+	return m.lastSetB
+}
+
+// Whether addr is the background fetch the PPU is making right now, rather
+// than a CPU access through $2007 or a debugger peek
+func (m *Mapper005) backgroundFetch(addr uint16) bool {
+	return m.fetching && !m.spriteFetch && addr == m.lastAddr
+}
+
+func (m *Mapper005) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		if m.backgroundFetch(addr) {
+			if m.split {
+				return m.readCHR(uint32(m.splitBank)*0x1000 + uint32(addr&0x0FF8) | uint32(m.splitY&0x07)), true
+			}
+			if m.exramMode == 1 {
+				bank := uint32(m.chrUpper)<<6 | uint32(m.exAttr&0x3F)
+				return m.readCHR(bank*0x1000 + uint32(addr&0x0FFF)), true
+			}
+		}
+		return m.readCHR(m.chrOffset(addr, m.useSetB())), true
+	}
+	if addr > 0x3EFF {
+		return 0x00, false
+	}
+	offset := addr & 0x03FF
+	attribute := offset >= 0x03C0
+	if m.backgroundFetch(addr) {
+		if m.split {
+			// Split tiles come from ExRAM as a nametable with its own scroll
+			x := uint16(m.tile & 0x1F)
+			if !attribute {
+				return m.exram[m.splitY/8*32+x], true
+			}
+			attr := m.exram[0x03C0+m.splitY/32*8+x/4]
+			shift := (m.splitY/16&0x01)*4 + (x/2&0x01)*2
+			return (attr >> shift & 0x03) * 0x55, true
+		}
+		if m.exramMode == 1 && attribute {
+			return (m.exAttr >> 6) * 0x55, true
+		}
+	}
+	switch m.ntMapping >> ((addr >> 10 & 0x03) * 2) & 0x03 {
+	case 0:
+		return m.ciram[offset], true
+	case 1:
+		return m.ciram[0x0400+offset], true
+	case 2:
+		if m.exramMode < 2 {
+			return m.exram[offset], true
+		}
+		return 0x00, true
+	}
+	if attribute {
+		return m.fillAttr * 0x55, true
+	}
+	return m.fillTile, true
+}
+
+func (m *Mapper005) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr, m.lastSetB), data)
+	}
+	if addr > 0x3EFF {
+		return false
+	}
+	offset := addr & 0x03FF
+	switch m.ntMapping >> ((addr >> 10 & 0x03) * 2) & 0x03 {
+	case 0:
+		m.ciram[offset] = data
+	case 1:
+		m.ciram[0x0400+offset] = data
+	case 2:
+		if m.exramMode < 2 {
+			m.exram[offset] = data
+		}
+	}
+	return true
+}
+
+// Three reads of the same nametable address end every rendered scanline,
+// the rest of the line is tracked by counting tile fetches from there
+func (m *Mapper005) PPUAddressSeen(addr uint16) {
+	m.fetching = m.idle < 3
+	m.idle = 0
+	if addr == m.lastAddr {
+		m.matches++
+	} else {
+		m.matches = 0
+	}
+	m.lastAddr = addr
+	if addr < 0x2000 || addr > 0x2FFF || addr&0x03FF >= 0x03C0 {
+		return
+	}
+	switch {
+	case m.matches == 2:
+		m.detectScanline()
+		m.tile = 2
+		m.spriteFetch = false
+	case m.matches > 0:
+		return
+	case m.spriteFetch:
+		m.spriteFetch = false
+		m.tile = 0
+	default:
+		m.tile++
+	}
+	m.exAttr = m.exram[addr&0x03FF]
+	m.split = false
+	if m.splitCtrl&0x80 != 0 && m.exramMode < 2 && m.tile < 34 {
+		threshold := m.splitCtrl & 0x1F
+		m.split = (m.splitCtrl&0x40 == 0) == (m.tile < threshold)
+		line := uint16(0)
+		if m.inFrame {
+			line = uint16(m.scanline)
+			if m.tile < 2 {
+				line++
+			}
+		}
+		m.splitY = (uint16(m.splitScroll) + line) % 240
+	}
+	// The fetch after the last tile is a dummy, sprites follow
+	if m.tile >= 34 {
+		m.spriteFetch = true
+	}
+}
+
+func (m *Mapper005) detectScanline() {
+	if !m.inFrame {
+		m.inFrame = true
+		m.scanline = 0
+		m.irqPending = false
+		return
+	}
+	m.scanline++
+	if m.scanline == m.irqCompare {
+		m.irqPending = true
+	}
+}
+
+// The frame is over once the PPU stops reading for three CPU cycles
+func (m *Mapper005) CPUClock() {
+	if m.idle < 3 {
+		m.idle++
+		return
+	}
+	m.inFrame = false
+	m.fetching = false
+	m.spriteFetch = false
+	m.tile = 2
+}
+
+func (m *Mapper005) IRQ() bool {
+	return m.irqPending && m.irqEnable
+}
+
+func (m *Mapper005) state() []any {
+	return []any{&m.prgMode, &m.chrMode, &m.ramProtect, &m.exramMode, &m.ntMapping, &m.fillTile, &m.fillAttr,
+		&m.prgBank, &m.chrBank, &m.chrUpper, &m.lastSetB, &m.splitCtrl, &m.splitScroll, &m.splitBank,
+		&m.irqCompare, &m.irqEnable, &m.irqPending, &m.multiplier, &m.ppuCtrl, &m.exram, &m.ciram,
+		&m.inFrame, &m.scanline, &m.idle, &m.fetching, &m.lastAddr, &m.matches, &m.tile, &m.spriteFetch,
+		&m.exAttr, &m.split, &m.splitY}
+}
+
+func (m *Mapper005) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper005) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper005) Reset() {
+	m.prgMode = 3
+	m.chrMode = 0
+	m.ramProtect = [2]uint8{}
+	m.exramMode = 0
+	m.ntMapping = 0x00
+	m.fillTile = 0x00
+	m.fillAttr = 0x00
+	m.prgBank = [5]uint8{0x00, 0xFF, 0xFF, 0xFF, 0xFF}
+	m.chrBank = [12]uint16{}
+	m.chrUpper = 0x00
+	m.lastSetB = false
+	m.splitCtrl = 0x00
+	m.splitScroll = 0x00
+	m.splitBank = 0x00
+	m.irqCompare = 0x00
+	m.irqEnable = false
+	m.irqPending = false
+	m.multiplier = [2]uint8{0xFF, 0xFF}
+	m.ppuCtrl = 0x00
+	m.inFrame = false
+	m.scanline = 0
+	m.idle = 3
+	m.fetching = false
+	m.lastAddr = 0x0000
+	m.matches = 0
+	m.tile = 2
+	m.spriteFetch = false
+	m.exAttr = 0x00
+	m.split = false
+	m.splitY = 0
+}
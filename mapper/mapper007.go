@@ -0,0 +1,85 @@
+package mapper
+
+import "io"
+
+// AxROM, switchable 32 KB PRG and single-screen mirroring selected by bit 4
+type Mapper007 struct {
+	base
+	conflicts bool
+	bank      uint8
+}
+
+func init() {
+	Register(7, func(config Config) (Mapper, error) {
+		if config.Submapper > 2 {
+			return nil, ErrSubmapper
+		}
+		return NewMapper007(config, config.Submapper == 2), nil
+	})
+}
+
+func NewMapper007(config Config, conflicts bool) *Mapper007 {
+	return &Mapper007{
+		base:      makeBase(config),
+		conflicts: conflicts,
+		bank:      0,
+	}
+}
+
+func (m *Mapper007) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(m.bank&0x07)*0x8000 + uint32(addr&0x7FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper007) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x8000 {
+		if m.conflicts {
+			rom, _ := m.CPURead(addr, true)
+			data &= rom
+		}
+		m.bank = data
+		return true
+	}
+	if addr >= 0x6000 {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper007) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper007) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper007) Mirroring() uint8 {
+	if m.bank&0x10 != 0 {
+		return MirrorOnescreenHigh
+	}
+	return MirrorOnescreenLow
+}
+
+func (m *Mapper007) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper007) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper007) Reset() {
+	m.bank = 0
+}
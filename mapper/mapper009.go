@@ -0,0 +1,168 @@
+package mapper
+
+import "io"
+
+// CHR latches of the MMC2 and MMC4. Each 4 KB half of the pattern tables
+// has an $FD and an $FE bank, fetching tile $FD or $FE flips the latch once
+// the fetch completes, so the trigger tile itself still uses the old bank
+type chrLatch struct {
+	banks   [2][2]uint8 // [half][FD, FE]
+	latch   [2]uint8
+	pending int8 // Half to flip on the next fetch, -1 for none
+	value   uint8
+}
+
+// MMC2 only watches $0FD8 and $0FE8 in the low half, MMC4 watches whole
+// 8 byte ranges in both halves. The PPU bus is idle while rendering is off,
+// so only real tile fetches and $2006/$2007 accesses reach the latch
+func (l *chrLatch) seen(addr uint16, exact bool) {
+	if l.pending >= 0 {
+		l.latch[l.pending] = l.value
+		l.pending = -1
+	}
+	if addr > 0x1FFF {
+		return
+	}
+	half := int8(addr >> 12)
+	tile := addr & 0x0FF8
+	if exact && half == 0 && addr&0x0FFF != 0x0FD8 && addr&0x0FFF != 0x0FE8 {
+		return
+	}
+	switch tile {
+	case 0x0FD8:
+		l.pending = half
+		l.value = 0
+	case 0x0FE8:
+		l.pending = half
+		l.value = 1
+	}
+}
+
+func (l *chrLatch) offset(addr uint16) uint32 {
+	half := addr >> 12 & 0x01
+	return uint32(l.banks[half][l.latch[half]])*0x1000 + uint32(addr&0x0FFF)
+}
+
+// Registers $B000-$EFFF load the banks, $F000 the mirroring
+func (l *chrLatch) write(addr uint16, data uint8) (mirror uint8, ok bool) {
+	switch addr & 0xF000 {
+	case 0xB000:
+		l.banks[0][0] = data & 0x1F
+	case 0xC000:
+		l.banks[0][1] = data & 0x1F
+	case 0xD000:
+		l.banks[1][0] = data & 0x1F
+	case 0xE000:
+		l.banks[1][1] = data & 0x1F
+	case 0xF000:
+		if data&0x01 != 0 {
+			return MirrorHorizontal, true
+		}
+		return MirrorVertical, true
+	}
+	return 0, false
+}
+
+func (l *chrLatch) state() []any {
+	return []any{&l.banks, &l.latch, &l.pending, &l.value}
+}
+
+func (l *chrLatch) reset() {
+	l.banks = [2][2]uint8{}
+	l.latch = [2]uint8{1, 1}
+	l.pending = -1
+	l.value = 0
+}
+
+// MMC2 (PxROM), Punch-Out!!. A switchable 8 KB PRG bank at $8000 with the
+// last three banks fixed
+type Mapper009 struct {
+	base
+	chr     chrLatch
+	prgBank uint8
+	mirror  uint8
+}
+
+func init() {
+	Register(9, func(config Config) (Mapper, error) {
+		return NewMapper009(config), nil
+	})
+}
+
+func NewMapper009(config Config) *Mapper009 {
+	m := &Mapper009{
+		base: makeBase(config),
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper009) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0xA000 {
+		return m.readPRG(uint32(len(m.prg)-0x6000) + uint32(addr-0xA000)), true
+	}
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(m.prgBank&0x0F)*0x2000 + uint32(addr&0x1FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper009) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0xA000 && addr <= 0xAFFF {
+		m.prgBank = data
+		return true
+	}
+	if addr >= 0xB000 {
+		if mirror, ok := m.chr.write(addr, data); ok {
+			m.mirror = mirror
+		}
+		return true
+	}
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper009) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chr.offset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper009) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chr.offset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper009) PPUAddressSeen(addr uint16) {
+	m.chr.seen(addr, true)
+}
+
+func (m *Mapper009) Mirroring() uint8 {
+	return m.mirror
+}
+
+func (m *Mapper009) state() []any {
+	return append([]any{&m.prgBank, &m.mirror}, m.chr.state()...)
+}
+
+func (m *Mapper009) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper009) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper009) Reset() {
+	m.chr.reset()
+	m.prgBank = 0x00
+	m.mirror = MirrorHardware
+}
@@ -0,0 +1,96 @@
+package mapper
+
+import "io"
+
+// MMC4 (FxROM), Fire Emblem. The CHR latches of the MMC2 with a switchable
+// 16 KB PRG bank at $8000, the last bank fixed and 8 KB of PRG-RAM
+type Mapper010 struct {
+	base
+	chr     chrLatch
+	prgBank uint8
+	mirror  uint8
+}
+
+func init() {
+	Register(10, func(config Config) (Mapper, error) {
+		return NewMapper010(config), nil
+	})
+}
+
+func NewMapper010(config Config) *Mapper010 {
+	m := &Mapper010{
+		base: makeBase(config),
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper010) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0xC000 {
+		return m.readPRG(uint32(len(m.prg)-0x4000) + uint32(addr&0x3FFF)), true
+	}
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(m.prgBank&0x0F)*0x4000 + uint32(addr&0x3FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper010) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0xA000 && addr <= 0xAFFF {
+		m.prgBank = data
+		return true
+	}
+	if addr >= 0xB000 {
+		if mirror, ok := m.chr.write(addr, data); ok {
+			m.mirror = mirror
+		}
+		return true
+	}
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper010) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chr.offset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper010) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chr.offset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper010) PPUAddressSeen(addr uint16) {
+	m.chr.seen(addr, false)
+}
+
+func (m *Mapper010) Mirroring() uint8 {
+	return m.mirror
+}
+
+func (m *Mapper010) state() []any {
+	return append([]any{&m.prgBank, &m.mirror}, m.chr.state()...)
+}
+
+func (m *Mapper010) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper010) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper010) Reset() {
+	m.chr.reset()
+	m.prgBank = 0x00
+	m.mirror = MirrorHardware
+}
@@ -0,0 +1,65 @@
+package mapper
+
+import "io"
+
+// Color Dreams, a 32 KB PRG bank in bits 0-1 and an 8 KB CHR bank in bits
+// 4-7 of a register with bus conflicts
+type Mapper011 struct {
+	base
+	bank uint8
+}
+
+func init() {
+	Register(11, func(config Config) (Mapper, error) {
+		return NewMapper011(config), nil
+	})
+}
+
+func NewMapper011(config Config) *Mapper011 {
+	return &Mapper011{
+		base: makeBase(config),
+		bank: 0,
+	}
+}
+
+func (m *Mapper011) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(m.bank&0x03)*0x8000 + uint32(addr&0x7FFF)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper011) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x8000 {
+		rom, _ := m.CPURead(addr, true)
+		m.bank = data & rom
+		return true
+	}
+	return false
+}
+
+func (m *Mapper011) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(m.bank>>4)*0x2000 + uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper011) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(m.bank>>4)*0x2000+uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper011) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper011) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper011) Reset() {
+	m.bank = 0
+}
@@ -0,0 +1,169 @@
+package mapper
+
+import "io"
+
+// Namco 163. Three 8 KB PRG banks, eight 1 KB CHR banks and four nametable
+// banks that can each point into CHR-ROM or the console's nametable RAM, a
+// 15-bit CPU cycle IRQ counter and wavetable sound
+type Mapper019 struct {
+	base
+	prgBank    [3]uint8
+	chrBank    [12]uint8 // Pattern tables, then the four nametables
+	chrRAMOff  uint8     // $E800 bits 6-7, stop $E0-$FF pattern banks selecting nametable RAM
+	ramProtect uint8     // $F800
+	irqCounter uint16
+	irqActive  bool
+	ciram      [2048]uint8 // Held by the board so nametables can interleave with CHR-ROM
+	audio      n163Audio
+}
+
+func init() {
+	Register(19, func(config Config) (Mapper, error) {
+		return NewMapper019(config), nil
+	})
+}
+
+func NewMapper019(config Config) *Mapper019 {
+	m := &Mapper019{
+		base: makeBase(config),
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper019) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	switch {
+	case addr >= 0xE000:
+		return m.readPRG(uint32(len(m.prg)-0x2000) + uint32(addr&0x1FFF)), true
+	case addr >= 0x8000:
+		return m.readPRG(uint32(m.prgBank[(addr-0x8000)>>13])*0x2000 + uint32(addr&0x1FFF)), true
+	case addr >= 0x6000:
+		return m.readRAM(uint32(addr & 0x1FFF))
+	case addr >= 0x5800:
+		return uint8(m.irqCounter >> 8), true
+	case addr >= 0x5000:
+		return uint8(m.irqCounter), true
+	case addr >= 0x4800:
+		return m.audio.read(readOnly), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper019) CPUWrite(addr uint16, data uint8) bool {
+	switch {
+	case addr >= 0xF800:
+		m.ramProtect = data
+		m.audio.address = data
+	case addr >= 0xF000:
+		m.prgBank[2] = data & 0x3F
+	case addr >= 0xE800:
+		m.prgBank[1] = data & 0x3F
+		m.chrRAMOff = data & 0xC0
+	case addr >= 0xE000:
+		m.prgBank[0] = data & 0x3F
+		m.audio.disabled = data&0x40 != 0
+	case addr >= 0x8000:
+		m.chrBank[(addr-0x8000)>>11] = data
+	case addr >= 0x6000:
+		// $F800 must read 0100 in its upper bits, the lower ones protect 2 KB each
+		if m.ramProtect&0xF0 != 0x40 || m.ramProtect&(0x01<<((addr-0x6000)>>11)) != 0 {
+			return false
+		}
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	case addr >= 0x5800:
+		m.irqCounter = m.irqCounter&0x00FF | uint16(data)<<8
+		m.irqActive = false
+	case addr >= 0x5000:
+		m.irqCounter = m.irqCounter&0xFF00 | uint16(data)
+		m.irqActive = false
+	case addr >= 0x4800:
+		m.audio.write(data)
+	default:
+		return false
+	}
+	return true
+}
+
+// Offset of a pattern or nametable access, in CHR-ROM or in nametable RAM.
+// Bank values $E0-$FF select a nametable RAM page, for pattern tables only
+// while the matching $E800 bit is clear
+func (m *Mapper019) chrOffset(addr uint16) (uint32, bool) {
+	slot := addr >> 10 & 0x0F
+	if slot >= 12 {
+		slot -= 4 // $3000-$3EFF mirrors the nametables
+	}
+	bank := m.chrBank[slot]
+	ciram := bank >= 0xE0
+	if slot < 8 && m.chrRAMOff&(0x40<<(slot>>2)) != 0 {
+		ciram = false
+	}
+	if ciram {
+		return uint32(bank&0x01)*0x0400 + uint32(addr&0x03FF), true
+	}
+	return uint32(bank)*0x0400 + uint32(addr&0x03FF), false
+}
+
+func (m *Mapper019) PPURead(addr uint16) (uint8, bool) {
+	if addr > 0x3EFF {
+		return 0x00, false
+	}
+	offset, ciram := m.chrOffset(addr)
+	if ciram {
+		return m.ciram[offset], true
+	}
+	return m.readCHR(offset), true
+}
+
+func (m *Mapper019) PPUWrite(addr uint16, data uint8) bool {
+	if addr > 0x3EFF {
+		return false
+	}
+	offset, ciram := m.chrOffset(addr)
+	if ciram {
+		m.ciram[offset] = data
+		return true
+	}
+	return m.writeCHR(offset, data)
+}
+
+// The counter counts up to $7FFF while bit 15 is set, raising an IRQ there
+func (m *Mapper019) CPUClock() {
+	if m.irqCounter&0x8000 != 0 && m.irqCounter&0x7FFF != 0x7FFF {
+		m.irqCounter++
+		if m.irqCounter&0x7FFF == 0x7FFF {
+			m.irqActive = true
+		}
+	}
+	m.audio.clock()
+}
+
+func (m *Mapper019) IRQ() bool {
+	return m.irqActive
+}
+
+func (m *Mapper019) AudioOutput() float32 {
+	return m.audio.output()
+}
+
+func (m *Mapper019) state() []any {
+	s := []any{&m.prgBank, &m.chrBank, &m.chrRAMOff, &m.ramProtect, &m.irqCounter, &m.irqActive, &m.ciram}
+	return append(s, m.audio.state()...)
+}
+
+func (m *Mapper019) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper019) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper019) Reset() {
+	m.prgBank = [3]uint8{0, 1, 2}
+	m.chrBank = [12]uint8{0, 1, 2, 3, 4, 5, 6, 7, 0xE0, 0xE1, 0xE0, 0xE1}
+	m.chrRAMOff = 0x00
+	m.ramProtect = 0x00
+	m.irqCounter = 0x0000
+	m.irqActive = false
+	m.audio.reset()
+}
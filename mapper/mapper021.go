@@ -0,0 +1,249 @@
+package mapper
+
+import "io"
+
+// Register select lines of a VRC2 or VRC4 board: which CPU address bits are
+// wired to the chip's A0 and A1, boards of unknown wiring connect both
+// candidates, which works because games only use one
+type vrcLines struct {
+	a0 uint16
+	a1 uint16
+}
+
+// VRC2 and VRC4, registered for mappers 21, 22, 23 and 25 whose boards
+// differ in address wiring. Two 8 KB PRG banks with a swap mode on the VRC4,
+// eight 1 KB CHR banks written a nibble at a time and, on the VRC4, the VRC
+// IRQ counter
+type Mapper021 struct {
+	base
+	lines    vrcLines
+	vrc2     bool
+	chrShift uint8 // VRC2a ignores the low bit of CHR bank numbers
+	prgBank  [2]uint8
+	prgSwap  bool
+	chrBank  [8]uint16
+	mirror   uint8
+	ramOn    bool
+	latch    uint8 // VRC2 boards without RAM have a 1-bit latch at $6000
+	irq      vrcIRQ
+}
+
+func init() {
+	Register(21, func(config Config) (Mapper, error) {
+		switch config.Submapper {
+		case 0:
+			return NewMapper021(config, vrcLines{a0: 0x0042, a1: 0x0084}, false), nil
+		case 1: // VRC4a
+			return NewMapper021(config, vrcLines{a0: 0x0002, a1: 0x0004}, false), nil
+		case 2: // VRC4c
+			return NewMapper021(config, vrcLines{a0: 0x0040, a1: 0x0080}, false), nil
+		}
+		return nil, ErrSubmapper
+	})
+	Register(22, func(config Config) (Mapper, error) {
+		if config.Submapper != 0 {
+			return nil, ErrSubmapper
+		}
+		// VRC2a
+		m := NewMapper021(config, vrcLines{a0: 0x0002, a1: 0x0001}, true)
+		m.chrShift = 1
+		return m, nil
+	})
+	Register(23, func(config Config) (Mapper, error) {
+		switch config.Submapper {
+		case 0:
+			return NewMapper021(config, vrcLines{a0: 0x0005, a1: 0x000A}, false), nil
+		case 1: // VRC4f
+			return NewMapper021(config, vrcLines{a0: 0x0001, a1: 0x0002}, false), nil
+		case 2: // VRC4e
+			return NewMapper021(config, vrcLines{a0: 0x0004, a1: 0x0008}, false), nil
+		case 3: // VRC2b
+			return NewMapper021(config, vrcLines{a0: 0x0001, a1: 0x0002}, true), nil
+		}
+		return nil, ErrSubmapper
+	})
+	Register(25, func(config Config) (Mapper, error) {
+		switch config.Submapper {
+		case 0:
+			return NewMapper021(config, vrcLines{a0: 0x000A, a1: 0x0005}, false), nil
+		case 1: // VRC4b
+			return NewMapper021(config, vrcLines{a0: 0x0002, a1: 0x0001}, false), nil
+		case 2: // VRC4d
+			return NewMapper021(config, vrcLines{a0: 0x0008, a1: 0x0004}, false), nil
+		case 3: // VRC2c
+			return NewMapper021(config, vrcLines{a0: 0x0002, a1: 0x0001}, true), nil
+		}
+		return nil, ErrSubmapper
+	})
+}
+
+func NewMapper021(config Config, lines vrcLines, vrc2 bool) *Mapper021 {
+	m := &Mapper021{
+		base:  makeBase(config),
+		lines: lines,
+		vrc2:  vrc2,
+	}
+	m.Reset()
+	return m
+}
+
+// Register number 0-3 within a $1000 block
+func (l vrcLines) register(addr uint16) uint16 {
+	var reg uint16
+	if addr&l.a0 != 0 {
+		reg |= 0x01
+	}
+	if addr&l.a1 != 0 {
+		reg |= 0x02
+	}
+	return reg
+}
+
+func (m *Mapper021) prgOffset(addr uint16) uint32 {
+	banks := uint32(len(m.prg) / 0x2000)
+	var bank uint32
+	switch (addr >> 13) & 0x03 {
+	case 0:
+		bank = uint32(m.prgBank[0])
+		if m.prgSwap {
+			bank = banks - 2
+		}
+	case 1:
+		bank = uint32(m.prgBank[1])
+	case 2:
+		bank = banks - 2
+		if m.prgSwap {
+			bank = uint32(m.prgBank[0])
+		}
+	case 3:
+		bank = banks - 1
+	}
+	return bank*0x2000 + uint32(addr&0x1FFF)
+}
+
+func (m *Mapper021) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(m.prgOffset(addr)), true
+	}
+	if addr >= 0x6000 {
+		if len(m.ram) == 0 && m.vrc2 && addr <= 0x6FFF {
+			return m.latch, true
+		}
+		if m.ramOn || m.vrc2 {
+			return m.readRAM(uint32(addr & 0x1FFF))
+		}
+	}
+	return 0x00, false
+}
+
+func (m *Mapper021) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		if len(m.ram) == 0 && m.vrc2 && addr <= 0x6FFF {
+			m.latch = data & 0x01
+			return true
+		}
+		if m.ramOn || m.vrc2 {
+			return m.writeRAM(uint32(addr&0x1FFF), data)
+		}
+		return false
+	}
+	if addr < 0x8000 {
+		return false
+	}
+	reg := m.lines.register(addr)
+	switch addr & 0xF000 {
+	case 0x8000:
+		m.prgBank[0] = data & 0x1F
+	case 0x9000:
+		switch {
+		case m.vrc2:
+			m.mirror = MirrorVertical
+			if data&0x01 != 0 {
+				m.mirror = MirrorHorizontal
+			}
+		case reg == 0:
+			m.mirror = [4]uint8{MirrorVertical, MirrorHorizontal, MirrorOnescreenLow, MirrorOnescreenHigh}[data&0x03]
+		case reg == 2:
+			m.ramOn = data&0x01 != 0
+			m.prgSwap = data&0x02 != 0
+		}
+	case 0xA000:
+		m.prgBank[1] = data & 0x1F
+	case 0xB000, 0xC000, 0xD000, 0xE000:
+		// Each bank takes two registers, the low then the high nibble
+		bank := ((addr>>12)-0x0B)*2 + reg>>1
+		if reg&0x01 == 0 {
+			m.chrBank[bank] = m.chrBank[bank]&0x01F0 | uint16(data&0x0F)
+		} else {
+			m.chrBank[bank] = m.chrBank[bank]&0x000F | uint16(data&0x1F)<<4
+		}
+	case 0xF000:
+		if m.vrc2 {
+			break
+		}
+		switch reg {
+		case 0:
+			m.irq.writeLatch(m.irq.latch&0xF0 | data&0x0F)
+		case 1:
+			m.irq.writeLatch(m.irq.latch&0x0F | data<<4)
+		case 2:
+			m.irq.writeControl(data)
+		case 3:
+			m.irq.acknowledge()
+		}
+	}
+	return true
+}
+
+func (m *Mapper021) chrOffset(addr uint16) uint32 {
+	bank := uint32(m.chrBank[addr>>10&0x07] >> m.chrShift)
+	return bank*0x0400 + uint32(addr&0x03FF)
+}
+
+func (m *Mapper021) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper021) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper021) Mirroring() uint8 {
+	return m.mirror
+}
+
+func (m *Mapper021) IRQ() bool {
+	return m.irq.active
+}
+
+func (m *Mapper021) CPUClock() {
+	m.irq.clock()
+}
+
+func (m *Mapper021) state() []any {
+	return append([]any{&m.prgBank, &m.prgSwap, &m.chrBank, &m.mirror, &m.ramOn, &m.latch}, m.irq.state()...)
+}
+
+func (m *Mapper021) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper021) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper021) Reset() {
+	m.prgBank = [2]uint8{0, 1}
+	m.prgSwap = false
+	m.chrBank = [8]uint16{}
+	m.mirror = MirrorHardware
+	m.ramOn = false
+	m.latch = 0x00
+	m.irq.reset()
+}
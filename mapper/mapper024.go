@@ -0,0 +1,171 @@
+package mapper
+
+import "io"
+
+// VRC6, registered for mappers 24 and 26 which swap A0 and A1. A 16 KB and
+// an 8 KB PRG bank, eight 1 KB CHR banks and two pulse plus one sawtooth
+// expansion sound channels
+type Mapper024 struct {
+	base
+	lines   vrcLines
+	prgBank [2]uint8
+	chrBank [8]uint8
+	control uint8 // $B003, PPU banking mode, mirroring and PRG-RAM enable
+	irq     vrcIRQ
+	audio   vrc6Audio
+}
+
+func init() {
+	Register(24, func(config Config) (Mapper, error) {
+		return NewMapper024(config, vrcLines{a0: 0x0001, a1: 0x0002}), nil
+	})
+	Register(26, func(config Config) (Mapper, error) {
+		return NewMapper024(config, vrcLines{a0: 0x0002, a1: 0x0001}), nil
+	})
+}
+
+func NewMapper024(config Config, lines vrcLines) *Mapper024 {
+	m := &Mapper024{
+		base:  makeBase(config),
+		lines: lines,
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper024) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	switch {
+	case addr >= 0xE000:
+		return m.readPRG(uint32(len(m.prg)-0x2000) + uint32(addr&0x1FFF)), true
+	case addr >= 0xC000:
+		return m.readPRG(uint32(m.prgBank[1])*0x2000 + uint32(addr&0x1FFF)), true
+	case addr >= 0x8000:
+		return m.readPRG(uint32(m.prgBank[0])*0x4000 + uint32(addr&0x3FFF)), true
+	case addr >= 0x6000 && m.control&0x80 != 0:
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper024) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		if m.control&0x80 == 0 {
+			return false
+		}
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	if addr < 0x8000 {
+		return false
+	}
+	reg := m.lines.register(addr)
+	switch addr & 0xF000 {
+	case 0x8000:
+		m.prgBank[0] = data & 0x0F
+	case 0x9000:
+		if reg == 3 {
+			m.audio.writeControl(data)
+		} else {
+			m.audio.pulse[0].write(reg, data)
+		}
+	case 0xA000:
+		if reg != 3 {
+			m.audio.pulse[1].write(reg, data)
+		}
+	case 0xB000:
+		if reg == 3 {
+			m.control = data
+		} else {
+			m.audio.saw.write(reg, data)
+		}
+	case 0xC000:
+		m.prgBank[1] = data & 0x1F
+	case 0xD000:
+		m.chrBank[reg] = data
+	case 0xE000:
+		m.chrBank[4+reg] = data
+	case 0xF000:
+		switch reg {
+		case 0:
+			m.irq.writeLatch(data)
+		case 1:
+			m.irq.writeControl(data)
+		case 2:
+			m.irq.acknowledge()
+		}
+	}
+	return true
+}
+
+// Mode 0 has eight 1 KB banks, mode 1 four 2 KB banks and modes 2 and 3
+// mix the two, 1 KB banks below $1000 and 2 KB banks above. Bit 5 of the
+// control register takes A10 of a 2 KB bank from the PPU
+func (m *Mapper024) chrOffset(addr uint16) uint32 {
+	mode := m.control & 0x03
+	slot := addr >> 10 & 0x07
+	var bank uint8
+	if mode == 0 || (mode >= 2 && addr < 0x1000) {
+		bank = m.chrBank[slot]
+	} else {
+		if mode == 1 {
+			bank = m.chrBank[slot>>1]
+		} else {
+			bank = m.chrBank[4+(slot-4)>>1]
+		}
+		if m.control&0x20 != 0 {
+			bank = bank&0xFE | uint8(slot&0x01)
+		}
+	}
+	return uint32(bank)*0x0400 + uint32(addr&0x03FF)
+}
+
+func (m *Mapper024) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper024) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper024) Mirroring() uint8 {
+	return [4]uint8{MirrorVertical, MirrorHorizontal, MirrorOnescreenLow, MirrorOnescreenHigh}[m.control>>2&0x03]
+}
+
+func (m *Mapper024) IRQ() bool {
+	return m.irq.active
+}
+
+func (m *Mapper024) CPUClock() {
+	m.irq.clock()
+	m.audio.clock()
+}
+
+func (m *Mapper024) AudioOutput() float32 {
+	return m.audio.output()
+}
+
+func (m *Mapper024) state() []any {
+	s := append([]any{&m.prgBank, &m.chrBank, &m.control}, m.irq.state()...)
+	return append(s, m.audio.state()...)
+}
+
+func (m *Mapper024) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper024) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper024) Reset() {
+	m.prgBank = [2]uint8{0, 0}
+	m.chrBank = [8]uint8{}
+	m.control = 0x00
+	m.irq.reset()
+	m.audio.reset()
+}
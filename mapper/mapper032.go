@@ -0,0 +1,127 @@
+package mapper
+
+import "io"
+
+// Irem G-101. Two 8 KB PRG banks with a mode swapping the first one with
+// the fixed $C000 bank, eight 1 KB CHR banks and switchable mirroring. The
+// Major League board (submapper 1) is wired for one screen and no swap
+type Mapper032 struct {
+	base
+	majorLeague bool
+	prgBank     [2]uint8
+	prgSwap     bool
+	chrBank     [8]uint8
+	mirror      uint8
+}
+
+func init() {
+	Register(32, func(config Config) (Mapper, error) {
+		if config.Submapper > 1 {
+			return nil, ErrSubmapper
+		}
+		return NewMapper032(config, config.Submapper == 1), nil
+	})
+}
+
+func NewMapper032(config Config, majorLeague bool) *Mapper032 {
+	m := &Mapper032{
+		base:        makeBase(config),
+		majorLeague: majorLeague,
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper032) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr < 0x6000 {
+		return 0x00, false
+	}
+	if addr < 0x8000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	secondLast := uint32(len(m.prg)/0x2000 - 2)
+	var bank uint32
+	switch (addr >> 13) & 0x03 {
+	case 0:
+		bank = uint32(m.prgBank[0])
+		if m.prgSwap {
+			bank = secondLast
+		}
+	case 1:
+		bank = uint32(m.prgBank[1])
+	case 2:
+		bank = secondLast
+		if m.prgSwap {
+			bank = uint32(m.prgBank[0])
+		}
+	case 3:
+		bank = secondLast + 1
+	}
+	return m.readPRG(bank*0x2000 + uint32(addr&0x1FFF)), true
+}
+
+func (m *Mapper032) CPUWrite(addr uint16, data uint8) bool {
+	if addr < 0x6000 {
+		return false
+	}
+	if addr < 0x8000 {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	switch addr & 0xF000 {
+	case 0x8000:
+		m.prgBank[0] = data & 0x1F
+	case 0x9000:
+		if !m.majorLeague {
+			m.prgSwap = data&0x02 != 0
+			m.mirror = MirrorVertical
+			if data&0x01 != 0 {
+				m.mirror = MirrorHorizontal
+			}
+		}
+	case 0xA000:
+		m.prgBank[1] = data & 0x1F
+	case 0xB000:
+		m.chrBank[addr&0x07] = data
+	}
+	return true
+}
+
+func (m *Mapper032) chrOffset(addr uint16) uint32 {
+	return uint32(m.chrBank[addr>>10&0x07])*0x0400 + uint32(addr&0x03FF)
+}
+
+func (m *Mapper032) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper032) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper032) Mirroring() uint8 {
+	return m.mirror
+}
+
+func (m *Mapper032) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.prgBank, &m.prgSwap, &m.chrBank, &m.mirror)
+}
+
+func (m *Mapper032) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.prgBank, &m.prgSwap, &m.chrBank, &m.mirror)
+}
+
+func (m *Mapper032) Reset() {
+	m.prgBank = [2]uint8{0, 1}
+	m.prgSwap = false
+	m.chrBank = [8]uint8{}
+	m.mirror = MirrorHardware
+	if m.majorLeague {
+		m.mirror = MirrorOnescreenLow
+	}
+}
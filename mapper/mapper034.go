@@ -0,0 +1,106 @@
+package mapper
+
+import "io"
+
+// BNROM and NINA-001, two unrelated boards sharing a number. BNROM switches
+// 32 KB of PRG through $8000-$FFFF with bus conflicts. NINA-001 has its
+// registers at $7FFD-$7FFF on top of the PRG-RAM: a 32 KB PRG bank and two
+// 4 KB CHR banks
+type Mapper034 struct {
+	base
+	nina    bool
+	prgBank uint8
+	chrBank [2]uint8
+}
+
+func init() {
+	Register(34, func(config Config) (Mapper, error) {
+		switch config.Submapper {
+		case 0:
+			// Only NINA-001 has CHR-ROM to switch
+			return NewMapper034(config, config.CHRROMSize > 0x2000), nil
+		case 1:
+			return NewMapper034(config, true), nil
+		case 2:
+			return NewMapper034(config, false), nil
+		}
+		return nil, ErrSubmapper
+	})
+}
+
+func NewMapper034(config Config, nina bool) *Mapper034 {
+	return &Mapper034{
+		base:    makeBase(config),
+		nina:    nina,
+		prgBank: 0,
+		chrBank: [2]uint8{0, 1},
+	}
+}
+
+func (m *Mapper034) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(m.prgBank)*0x8000 + uint32(addr&0x7FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper034) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x8000 {
+		if !m.nina {
+			rom, _ := m.CPURead(addr, true)
+			m.prgBank = data & rom
+		}
+		return true
+	}
+	if addr >= 0x6000 {
+		if m.nina {
+			switch addr {
+			case 0x7FFD:
+				m.prgBank = data & 0x01
+			case 0x7FFE:
+				m.chrBank[0] = data & 0x0F
+			case 0x7FFF:
+				m.chrBank[1] = data & 0x0F
+			}
+		}
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper034) chrOffset(addr uint16) uint32 {
+	if !m.nina {
+		return uint32(addr)
+	}
+	return uint32(m.chrBank[addr>>12])*0x1000 + uint32(addr&0x0FFF)
+}
+
+func (m *Mapper034) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper034) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper034) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.prgBank, &m.chrBank)
+}
+
+func (m *Mapper034) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.prgBank, &m.chrBank)
+}
+
+func (m *Mapper034) Reset() {
+	m.prgBank = 0
+	m.chrBank = [2]uint8{0, 1}
+}
@@ -0,0 +1,134 @@
+package mapper
+
+import "io"
+
+// Irem H3001. Three 8 KB PRG banks, eight 1 KB CHR banks, switchable
+// mirroring and a 16-bit IRQ counter that counts CPU cycles down to zero
+type Mapper065 struct {
+	base
+	prgBank    [3]uint8
+	chrBank    [8]uint8
+	mirror     uint8
+	irqReload  uint16
+	irqCounter uint16
+	irqEnable  bool
+	irqActive  bool
+}
+
+func init() {
+	Register(65, func(config Config) (Mapper, error) {
+		return NewMapper065(config), nil
+	})
+}
+
+func NewMapper065(config Config) *Mapper065 {
+	m := &Mapper065{
+		base: makeBase(config),
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper065) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	switch {
+	case addr >= 0xE000:
+		return m.readPRG(uint32(len(m.prg)-0x2000) + uint32(addr&0x1FFF)), true
+	case addr >= 0x8000:
+		return m.readPRG(uint32(m.prgBank[(addr-0x8000)>>13])*0x2000 + uint32(addr&0x1FFF)), true
+	case addr >= 0x6000:
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper065) CPUWrite(addr uint16, data uint8) bool {
+	if addr < 0x6000 {
+		return false
+	}
+	if addr < 0x8000 {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	switch addr & 0xF000 {
+	case 0x8000:
+		m.prgBank[0] = data
+	case 0xA000:
+		m.prgBank[1] = data
+	case 0xC000:
+		m.prgBank[2] = data
+	case 0xB000:
+		m.chrBank[addr&0x07] = data
+	case 0x9000:
+		switch addr & 0x0007 {
+		case 1:
+			m.mirror = MirrorVertical
+			if data&0x80 != 0 {
+				m.mirror = MirrorHorizontal
+			}
+		case 3:
+			m.irqEnable = data&0x80 != 0
+			m.irqActive = false
+		case 4:
+			m.irqCounter = m.irqReload
+			m.irqActive = false
+		case 5:
+			m.irqReload = m.irqReload&0x00FF | uint16(data)<<8
+		case 6:
+			m.irqReload = m.irqReload&0xFF00 | uint16(data)
+		}
+	}
+	return true
+}
+
+func (m *Mapper065) chrOffset(addr uint16) uint32 {
+	return uint32(m.chrBank[addr>>10&0x07])*0x0400 + uint32(addr&0x03FF)
+}
+
+func (m *Mapper065) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper065) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper065) Mirroring() uint8 {
+	return m.mirror
+}
+
+// The counter stops at zero, raising the IRQ as it gets there
+func (m *Mapper065) CPUClock() {
+	if m.irqEnable && m.irqCounter > 0 {
+		m.irqCounter--
+		if m.irqCounter == 0 {
+			m.irqActive = true
+		}
+	}
+}
+
+func (m *Mapper065) IRQ() bool {
+	return m.irqActive
+}
+
+func (m *Mapper065) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.prgBank, &m.chrBank, &m.mirror, &m.irqReload, &m.irqCounter, &m.irqEnable, &m.irqActive)
+}
+
+func (m *Mapper065) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.prgBank, &m.chrBank, &m.mirror, &m.irqReload, &m.irqCounter, &m.irqEnable, &m.irqActive)
+}
+
+func (m *Mapper065) Reset() {
+	m.prgBank = [3]uint8{0x00, 0x01, 0xFE}
+	m.chrBank = [8]uint8{}
+	m.mirror = MirrorHardware
+	m.irqReload = 0x0000
+	m.irqCounter = 0x0000
+	m.irqEnable = false
+	m.irqActive = false
+}
@@ -0,0 +1,71 @@
+package mapper
+
+import "io"
+
+// GxROM, a 32 KB PRG bank in bits 4-5 and an 8 KB CHR bank in bits 0-1 of
+// a register with bus conflicts
+type Mapper066 struct {
+	base
+	bank uint8
+}
+
+func init() {
+	Register(66, func(config Config) (Mapper, error) {
+		return NewMapper066(config), nil
+	})
+}
+
+func NewMapper066(config Config) *Mapper066 {
+	return &Mapper066{
+		base: makeBase(config),
+		bank: 0,
+	}
+}
+
+func (m *Mapper066) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(m.bank>>4&0x03)*0x8000 + uint32(addr&0x7FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper066) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x8000 {
+		rom, _ := m.CPURead(addr, true)
+		m.bank = data & rom
+		return true
+	}
+	if addr >= 0x6000 {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper066) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(m.bank&0x03)*0x2000 + uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper066) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(m.bank&0x03)*0x2000+uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper066) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper066) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper066) Reset() {
+	m.bank = 0
+}
@@ -0,0 +1,156 @@
+package mapper
+
+import "io"
+
+// Sunsoft FME-7 and 5B. Registers are picked through $8000 and loaded
+// through $A000: eight 1 KB CHR banks, a ROM or RAM bank at $6000, three
+// 8 KB PRG banks and a 16-bit CPU cycle IRQ counter. The 5B adds three
+// AY-3-8910 square channels on $C000 and $E000
+type Mapper069 struct {
+	base
+	command    uint8
+	chrBank    [8]uint8
+	prgBank    [4]uint8 // $6000 bank with its RAM select and enable bits, then $8000-$DFFF
+	mirror     uint8
+	irqControl uint8
+	irqCounter uint16
+	irqActive  bool
+	audio      s5bAudio
+}
+
+func init() {
+	Register(69, func(config Config) (Mapper, error) {
+		return NewMapper069(config), nil
+	})
+}
+
+func NewMapper069(config Config) *Mapper069 {
+	m := &Mapper069{
+		base: makeBase(config),
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper069) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	switch {
+	case addr >= 0xE000:
+		return m.readPRG(uint32(len(m.prg)-0x2000) + uint32(addr&0x1FFF)), true
+	case addr >= 0x8000:
+		return m.readPRG(uint32(m.prgBank[1+(addr-0x8000)>>13])*0x2000 + uint32(addr&0x1FFF)), true
+	case addr >= 0x6000:
+		bank := m.prgBank[0]
+		if bank&0x40 == 0 {
+			return m.readPRG(uint32(bank&0x3F)*0x2000 + uint32(addr&0x1FFF)), true
+		}
+		if bank&0x80 != 0 {
+			return m.readRAM(uint32(bank&0x3F)*0x2000 + uint32(addr&0x1FFF))
+		}
+	}
+	return 0x00, false
+}
+
+func (m *Mapper069) CPUWrite(addr uint16, data uint8) bool {
+	switch {
+	case addr >= 0xE000:
+		m.audio.writeData(data)
+	case addr >= 0xC000:
+		m.audio.writeAddress(data)
+	case addr >= 0xA000:
+		m.writeParameter(data)
+	case addr >= 0x8000:
+		m.command = data & 0x0F
+	case addr >= 0x6000:
+		if m.prgBank[0]&0xC0 != 0xC0 {
+			return false
+		}
+		return m.writeRAM(uint32(m.prgBank[0]&0x3F)*0x2000+uint32(addr&0x1FFF), data)
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *Mapper069) writeParameter(data uint8) {
+	switch cmd := m.command; {
+	case cmd <= 0x07:
+		m.chrBank[cmd] = data
+	case cmd <= 0x0B:
+		m.prgBank[cmd-0x08] = data
+	case cmd == 0x0C:
+		m.mirror = [4]uint8{MirrorVertical, MirrorHorizontal, MirrorOnescreenLow, MirrorOnescreenHigh}[data&0x03]
+	case cmd == 0x0D:
+		m.irqControl = data
+		m.irqActive = false
+	case cmd == 0x0E:
+		m.irqCounter = m.irqCounter&0xFF00 | uint16(data)
+	case cmd == 0x0F:
+		m.irqCounter = m.irqCounter&0x00FF | uint16(data)<<8
+	}
+}
+
+func (m *Mapper069) chrOffset(addr uint16) uint32 {
+	return uint32(m.chrBank[addr>>10&0x07])*0x0400 + uint32(addr&0x03FF)
+}
+
+func (m *Mapper069) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper069) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper069) Mirroring() uint8 {
+	return m.mirror
+}
+
+// Bit 7 of the control register runs the counter, bit 0 lets it raise an
+// IRQ when it wraps from $0000 to $FFFF
+func (m *Mapper069) CPUClock() {
+	if m.irqControl&0x80 != 0 {
+		m.irqCounter--
+		if m.irqCounter == 0xFFFF && m.irqControl&0x01 != 0 {
+			m.irqActive = true
+		}
+	}
+	m.audio.clock()
+}
+
+func (m *Mapper069) IRQ() bool {
+	return m.irqActive
+}
+
+func (m *Mapper069) AudioOutput() float32 {
+	return m.audio.output()
+}
+
+func (m *Mapper069) state() []any {
+	s := []any{&m.command, &m.chrBank, &m.prgBank, &m.mirror, &m.irqControl, &m.irqCounter, &m.irqActive}
+	return append(s, m.audio.state()...)
+}
+
+func (m *Mapper069) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper069) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper069) Reset() {
+	m.command = 0x00
+	m.chrBank = [8]uint8{}
+	m.prgBank = [4]uint8{}
+	m.mirror = MirrorVertical
+	m.irqControl = 0x00
+	m.irqCounter = 0x0000
+	m.irqActive = false
+	m.audio.reset()
+}
@@ -0,0 +1,89 @@
+package mapper
+
+import "io"
+
+// Camerica BF909x, UxROM without bus conflicts and the bank register at
+// $C000-$FFFF. The Fire Hawk board (submapper 1) selects a single screen
+// through bit 4 of $8000-$9FFF
+type Mapper071 struct {
+	base
+	fireHawk bool
+	bank     uint8
+	mirror   uint8
+}
+
+func init() {
+	Register(71, func(config Config) (Mapper, error) {
+		if config.Submapper > 1 {
+			return nil, ErrSubmapper
+		}
+		return NewMapper071(config, config.Submapper == 1), nil
+	})
+}
+
+func NewMapper071(config Config, fireHawk bool) *Mapper071 {
+	return &Mapper071{
+		base:     makeBase(config),
+		fireHawk: fireHawk,
+		bank:     0,
+		mirror:   MirrorHardware,
+	}
+}
+
+func (m *Mapper071) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 && addr <= 0xBFFF {
+		return m.readPRG(uint32(m.bank)*0x4000 + uint32(addr&0x3FFF)), true
+	}
+	if addr >= 0xC000 {
+		return m.readPRG(uint32(len(m.prg)-0x4000) + uint32(addr&0x3FFF)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper071) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0xC000 {
+		m.bank = data
+		return true
+	}
+	if addr >= 0x8000 {
+		if m.fireHawk && addr <= 0x9FFF {
+			m.mirror = MirrorOnescreenLow
+			if data&0x10 != 0 {
+				m.mirror = MirrorOnescreenHigh
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (m *Mapper071) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper071) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper071) Mirroring() uint8 {
+	return m.mirror
+}
+
+func (m *Mapper071) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank, &m.mirror)
+}
+
+func (m *Mapper071) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank, &m.mirror)
+}
+
+func (m *Mapper071) Reset() {
+	m.bank = 0
+	m.mirror = MirrorHardware
+}
@@ -0,0 +1,162 @@
+package mapper
+
+import "io"
+
+// VRC7. Three 8 KB PRG banks, eight 1 KB CHR banks, the VRC IRQ counter and
+// a six channel FM synthesizer on the VRC7a boards
+type Mapper085 struct {
+	base
+	line    uint16 // Address line selecting the second register of each pair
+	prgBank [3]uint8
+	chrBank [8]uint8
+	control uint8 // $E000, mirroring, audio silence and PRG-RAM enable
+	irq     vrcIRQ
+	audio   vrc7Audio
+}
+
+func init() {
+	Register(85, func(config Config) (Mapper, error) {
+		// VRC7b (1) uses A3 and VRC7a (2) uses A4, unknown boards decode both
+		line := uint16(0x0018)
+		switch config.Submapper {
+		case 0:
+		case 1:
+			line = 0x0008
+		case 2:
+			line = 0x0010
+		default:
+			return nil, ErrSubmapper
+		}
+		return NewMapper085(config, line), nil
+	})
+}
+
+func NewMapper085(config Config, line uint16) *Mapper085 {
+	m := &Mapper085{
+		base: makeBase(config),
+		line: line,
+	}
+	m.Reset()
+	return m
+}
+
+func (m *Mapper085) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	switch {
+	case addr >= 0xE000:
+		return m.readPRG(uint32(len(m.prg)-0x2000) + uint32(addr&0x1FFF)), true
+	case addr >= 0x8000:
+		return m.readPRG(uint32(m.prgBank[(addr-0x8000)>>13])*0x2000 + uint32(addr&0x1FFF)), true
+	case addr >= 0x6000 && m.control&0x80 != 0:
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper085) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		if m.control&0x80 == 0 {
+			return false
+		}
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	if addr < 0x8000 {
+		return false
+	}
+	high := addr&m.line != 0
+	switch addr & 0xF000 {
+	case 0x8000:
+		if high {
+			m.prgBank[1] = data & 0x3F
+		} else {
+			m.prgBank[0] = data & 0x3F
+		}
+	case 0x9000:
+		// The audio ports are decoded on A4 and A5 regardless of board
+		switch addr & 0xF030 {
+		case 0x9010:
+			m.audio.writeAddress(data)
+		case 0x9030:
+			m.audio.writeData(data)
+		default:
+			if !high {
+				m.prgBank[2] = data & 0x3F
+			}
+		}
+	case 0xA000, 0xB000, 0xC000, 0xD000:
+		slot := (addr - 0xA000) >> 12 << 1
+		if high {
+			slot++
+		}
+		m.chrBank[slot] = data
+	case 0xE000:
+		if high {
+			m.irq.writeLatch(data)
+		} else {
+			m.control = data
+			m.audio.silenced = data&0x40 != 0
+		}
+	case 0xF000:
+		if high {
+			m.irq.acknowledge()
+		} else {
+			m.irq.writeControl(data)
+		}
+	}
+	return true
+}
+
+func (m *Mapper085) chrOffset(addr uint16) uint32 {
+	return uint32(m.chrBank[addr>>10&0x07])*0x0400 + uint32(addr&0x03FF)
+}
+
+func (m *Mapper085) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper085) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper085) Mirroring() uint8 {
+	return [4]uint8{MirrorVertical, MirrorHorizontal, MirrorOnescreenLow, MirrorOnescreenHigh}[m.control&0x03]
+}
+
+func (m *Mapper085) IRQ() bool {
+	return m.irq.active
+}
+
+func (m *Mapper085) CPUClock() {
+	m.irq.clock()
+	m.audio.clock()
+}
+
+func (m *Mapper085) AudioOutput() float32 {
+	return m.audio.output()
+}
+
+func (m *Mapper085) state() []any {
+	s := append([]any{&m.prgBank, &m.chrBank, &m.control}, m.irq.state()...)
+	return append(s, m.audio.state()...)
+}
+
+func (m *Mapper085) SaveState(w io.Writer) error {
+	return m.saveState(w, m.state()...)
+}
+
+func (m *Mapper085) LoadState(r io.Reader) error {
+	return m.loadState(r, m.state()...)
+}
+
+func (m *Mapper085) Reset() {
+	m.prgBank = [3]uint8{0, 0, 0}
+	m.chrBank = [8]uint8{}
+	m.control = 0x00
+	m.irq.reset()
+	m.audio.reset()
+}
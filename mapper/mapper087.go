@@ -0,0 +1,64 @@
+package mapper
+
+import "io"
+
+// Jaleco J87, fixed PRG and an 8 KB CHR bank written to $6000-$7FFF with
+// the two bank bits swapped
+type Mapper087 struct {
+	base
+	bank uint8
+}
+
+func init() {
+	Register(87, func(config Config) (Mapper, error) {
+		return NewMapper087(config), nil
+	})
+}
+
+func NewMapper087(config Config) *Mapper087 {
+	return &Mapper087{
+		base: makeBase(config),
+		bank: 0,
+	}
+}
+
+func (m *Mapper087) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(addr & 0x7FFF)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper087) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		m.bank = (data&0x01)<<1 | (data>>1)&0x01
+		return true
+	}
+	return false
+}
+
+func (m *Mapper087) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(m.bank)*0x2000 + uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper087) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(m.bank)*0x2000+uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper087) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper087) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper087) Reset() {
+	m.bank = 0
+}
@@ -0,0 +1,74 @@
+package mapper
+
+import "io"
+
+// Vs. System board, bit 2 of writes to $4016 selects the CHR bank and, on
+// the 40 KB Gumshoe board, the first 8 KB PRG bank
+type Mapper099 struct {
+	base
+	bank uint8
+}
+
+func init() {
+	Register(99, func(config Config) (Mapper, error) {
+		return NewMapper099(config), nil
+	})
+}
+
+func NewMapper099(config Config) *Mapper099 {
+	return &Mapper099{
+		base: makeBase(config),
+		bank: 0,
+	}
+}
+
+func (m *Mapper099) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 && addr <= 0x9FFF && len(m.prg) > 0x8000 {
+		return m.readPRG(uint32(m.bank)*0x8000 + uint32(addr&0x1FFF)), true
+	}
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(addr & 0x7FFF)), true
+	}
+	if addr >= 0x6000 {
+		return m.readRAM(uint32(addr & 0x1FFF))
+	}
+	return 0x00, false
+}
+
+func (m *Mapper099) CPUWrite(addr uint16, data uint8) bool {
+	if addr == 0x4016 {
+		// Seen on the way to the controller strobe, not claimed
+		m.bank = data >> 2 & 0x01
+		return false
+	}
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		return m.writeRAM(uint32(addr&0x1FFF), data)
+	}
+	return false
+}
+
+func (m *Mapper099) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(m.bank)*0x2000 + uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper099) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper099) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper099) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper099) Reset() {
+	m.bank = 0
+}
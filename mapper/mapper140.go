@@ -0,0 +1,64 @@
+package mapper
+
+import "io"
+
+// Jaleco JF-11 and JF-14, GxROM with the register at $6000-$7FFF: a 32 KB
+// PRG bank in bits 4-5 and an 8 KB CHR bank in bits 0-3
+type Mapper140 struct {
+	base
+	bank uint8
+}
+
+func init() {
+	Register(140, func(config Config) (Mapper, error) {
+		return NewMapper140(config), nil
+	})
+}
+
+func NewMapper140(config Config) *Mapper140 {
+	return &Mapper140{
+		base: makeBase(config),
+		bank: 0,
+	}
+}
+
+func (m *Mapper140) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(m.bank>>4&0x03)*0x8000 + uint32(addr&0x7FFF)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper140) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		m.bank = data
+		return true
+	}
+	return false
+}
+
+func (m *Mapper140) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(m.bank&0x0F)*0x2000 + uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper140) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(m.bank&0x0F)*0x2000+uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper140) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper140) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper140) Reset() {
+	m.bank = 0
+}
@@ -0,0 +1,68 @@
+package mapper
+
+import "io"
+
+// UNROM wired the other way round for Crazy Climber, the first 16 KB fixed
+// at $8000 and the switchable bank at $C000, with bus conflicts
+type Mapper180 struct {
+	base
+	bank uint8
+}
+
+func init() {
+	Register(180, func(config Config) (Mapper, error) {
+		return NewMapper180(config), nil
+	})
+}
+
+func NewMapper180(config Config) *Mapper180 {
+	return &Mapper180{
+		base: makeBase(config),
+		bank: 0,
+	}
+}
+
+func (m *Mapper180) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0xC000 {
+		return m.readPRG(uint32(m.bank)*0x4000 + uint32(addr&0x3FFF)), true
+	}
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(addr & 0x3FFF)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper180) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x8000 {
+		rom, _ := m.CPURead(addr, true)
+		m.bank = data & rom
+		return true
+	}
+	return false
+}
+
+func (m *Mapper180) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(uint32(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper180) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(uint32(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper180) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper180) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper180) Reset() {
+	m.bank = 0
+}
@@ -0,0 +1,72 @@
+package mapper
+
+import "io"
+
+// Sunsoft-1, fixed PRG and two 4 KB CHR banks written to $6000-$7FFF, bits
+// 0-2 for $0000 and bits 4-6 for $1000
+type Mapper184 struct {
+	base
+	bank uint8
+}
+
+func init() {
+	Register(184, func(config Config) (Mapper, error) {
+		return NewMapper184(config), nil
+	})
+}
+
+func NewMapper184(config Config) *Mapper184 {
+	return &Mapper184{
+		base: makeBase(config),
+		bank: 0,
+	}
+}
+
+func (m *Mapper184) CPURead(addr uint16, readOnly bool) (uint8, bool) {
+	if addr >= 0x8000 {
+		return m.readPRG(uint32(addr & 0x7FFF)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper184) CPUWrite(addr uint16, data uint8) bool {
+	if addr >= 0x6000 && addr <= 0x7FFF {
+		m.bank = data
+		return true
+	}
+	return false
+}
+
+func (m *Mapper184) chrOffset(addr uint16) uint32 {
+	bank := m.bank & 0x07
+	if addr >= 0x1000 {
+		bank = m.bank >> 4 & 0x07
+	}
+	return uint32(bank)*0x1000 + uint32(addr&0x0FFF)
+}
+
+func (m *Mapper184) PPURead(addr uint16) (uint8, bool) {
+	if addr <= 0x1FFF {
+		return m.readCHR(m.chrOffset(addr)), true
+	}
+	return 0x00, false
+}
+
+func (m *Mapper184) PPUWrite(addr uint16, data uint8) bool {
+	if addr <= 0x1FFF {
+		return m.writeCHR(m.chrOffset(addr), data)
+	}
+	return false
+}
+
+func (m *Mapper184) SaveState(w io.Writer) error {
+	return m.saveState(w, &m.bank)
+}
+
+func (m *Mapper184) LoadState(r io.Reader) error {
+	return m.loadState(r, &m.bank)
+}
+
+func (m *Mapper184) Reset() {
+	m.bank = 0
+}
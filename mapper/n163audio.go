@@ -0,0 +1,96 @@
+package mapper
+
+const (
+	n163Divider = 15     // CPU cycles to update one channel
+	n163Volume  = 0.0025 // Per unit of sample times volume, averaged over the active channels
+)
+
+// Namco 163 wavetable sound. Up to eight channels keep their registers at
+// the top of a 128-byte RAM, which also holds their 4-bit waveforms. The
+// chip updates one channel every 15 CPU cycles, so each extra channel lowers
+// the update rate of the rest
+type n163Audio struct {
+	ram      [128]uint8
+	address  uint8 // Bit 7 auto-increments after each access
+	divider  int32
+	channel  uint8 // Channel being updated, counting down from 7
+	outputs  [8]int32
+	disabled bool
+}
+
+// Number of enabled channels, set by bits 4-6 of $7F
+func (a *n163Audio) channels() uint8 {
+	return (a.ram[0x7F]>>4)&0x07 + 1
+}
+
+func (a *n163Audio) read(readOnly bool) uint8 {
+	data := a.ram[a.address&0x7F]
+	if !readOnly {
+		a.step()
+	}
+	return data
+}
+
+func (a *n163Audio) write(data uint8) {
+	a.ram[a.address&0x7F] = data
+	a.step()
+}
+
+func (a *n163Audio) step() {
+	if a.address&0x80 != 0 {
+		a.address = 0x80 | (a.address+1)&0x7F
+	}
+}
+
+func (a *n163Audio) clock() {
+	a.divider--
+	if a.divider > 0 {
+		return
+	}
+	a.divider = n163Divider
+	base := 0x40 + uint16(a.channel)*8
+	r := a.ram[base : base+8]
+	freq := uint32(r[0]) | uint32(r[2])<<8 | uint32(r[4]&0x03)<<16
+	phase := uint32(r[1]) | uint32(r[3])<<8 | uint32(r[5])<<16
+	length := (256 - uint32(r[4]&0xFC)) << 16
+	phase = (phase + freq) % length
+	r[1], r[3], r[5] = uint8(phase), uint8(phase>>8), uint8(phase>>16)
+
+	sample := r[6] + uint8(phase>>16)
+	nibble := a.ram[sample>>1&0x7F]
+	if sample&0x01 != 0 {
+		nibble >>= 4
+	}
+	a.outputs[a.channel] = (int32(nibble&0x0F) - 8) * int32(r[7]&0x0F)
+
+	if a.channel <= 8-a.channels() {
+		a.channel = 7
+	} else {
+		a.channel--
+	}
+}
+
+func (a *n163Audio) output() float32 {
+	if a.disabled {
+		return 0
+	}
+	n := a.channels()
+	var sum int32
+	for ch := 8 - n; ch < 8; ch++ {
+		sum += a.outputs[ch]
+	}
+	return float32(sum) / float32(n) * n163Volume
+}
+
+func (a *n163Audio) state() []any {
+	return []any{&a.ram, &a.address, &a.divider, &a.channel, &a.outputs, &a.disabled}
+}
+
+func (a *n163Audio) reset() {
+	// The sound RAM survives a reset
+	a.address = 0x00
+	a.divider = n163Divider
+	a.channel = 7
+	a.outputs = [8]int32{}
+	a.disabled = false
+}
@@ -0,0 +1,52 @@
+package mapper
+
+import (
+	"errors"
+	"sort"
+)
+
+var ErrSubmapper = errors.New("mapper: unsupported submapper")
+
+// Board description passed to a mapper constructor, sizes are in bytes.
+// CHR holds the CHR-RAM when CHRROMSize is 0
+type Config struct {
+	ID         uint16
+	Submapper  uint8
+	PRGROMSize int
+	CHRROMSize int
+	PRGRAMSize int
+	CHRRAMSize int
+	PRG        []uint8
+	CHR        []uint8
+	PRGRAM     []uint8
+}
+
+// Returns an error when the board variant is not supported
+type Constructor func(config Config) (Mapper, error)
+
+var registry = map[uint16]Constructor{}
+
+// Called from init by each mapper implementation
+func Register(id uint16, constructor Constructor) {
+	if _, ok := registry[id]; ok {
+		panic("mapper: duplicate registration")
+	}
+	registry[id] = constructor
+}
+
+func Lookup(id uint16) (Constructor, bool) {
+	constructor, ok := registry[id]
+	return constructor, ok
+}
+
+// Registered mapper numbers in ascending order
+func Supported() []uint16 {
+	ids := make([]uint16, 0, len(registry))
+	for id := range registry {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i int, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
@@ -0,0 +1,155 @@
+package mapper
+
+import "math"
+
+// Output of the 5B's 32 envelope levels, 1.5 dB apart, level 0 is silent
+var s5bLevels = func() [32]float32 {
+	var t [32]float32
+	for i := 1; i < len(t); i++ {
+		t[i] = float32(math.Pow(10, -float64(31-i)*1.5/20))
+	}
+	return t
+}()
+
+const s5bVolume = 0.12 // One channel at full level against the APU mix
+
+// Sunsoft 5B sound, a YM2149F (AY-3-8910 compatible) clocked at the CPU
+// rate with a divide by 16 prescaler. Three square channels share a noise
+// generator and an envelope generator
+type s5bAudio struct {
+	registers  [16]uint8
+	address    uint8
+	prescaler  uint8
+	toneTimer  [3]uint16
+	toneOut    [3]bool
+	noiseTimer uint8
+	noiseHalf  bool
+	noiseLFSR  uint32
+	envTimer   uint16
+	envStep    uint8
+	envHolding bool
+	envDown    bool
+}
+
+func (a *s5bAudio) writeAddress(data uint8) {
+	a.address = data
+}
+
+// The upper four address bits must be clear to select a register
+func (a *s5bAudio) writeData(data uint8) {
+	if a.address&0xF0 != 0 {
+		return
+	}
+	a.registers[a.address] = data
+	if a.address == 0x0D {
+		// Restart the envelope, attack counts up
+		a.envTimer = 0
+		a.envStep = 0
+		a.envHolding = false
+		a.envDown = data&0x04 == 0
+	}
+}
+
+func (a *s5bAudio) tonePeriod(ch int) uint16 {
+	return uint16(a.registers[ch*2]) | uint16(a.registers[ch*2+1]&0x0F)<<8
+}
+
+func (a *s5bAudio) clock() {
+	a.prescaler++
+	if a.prescaler < 16 {
+		return
+	}
+	a.prescaler = 0
+	for ch := range a.toneTimer {
+		a.toneTimer[ch]++
+		if a.toneTimer[ch] >= max(a.tonePeriod(ch), 1) {
+			a.toneTimer[ch] = 0
+			a.toneOut[ch] = !a.toneOut[ch]
+		}
+	}
+	// Noise steps at half the tone rate
+	a.noiseHalf = !a.noiseHalf
+	if a.noiseHalf {
+		a.noiseTimer++
+		if a.noiseTimer >= max(a.registers[6]&0x1F, 1) {
+			a.noiseTimer = 0
+			feedback := (a.noiseLFSR ^ a.noiseLFSR>>3) & 0x01
+			a.noiseLFSR = a.noiseLFSR>>1 | feedback<<16
+		}
+	}
+	// The envelope has 32 steps, twice per tone clock
+	for i := 0; i < 2; i++ {
+		a.envTimer++
+		if a.envTimer >= max(uint16(a.registers[11])|uint16(a.registers[12])<<8, 1) {
+			a.envTimer = 0
+			a.clockEnvelope()
+		}
+	}
+}
+
+// Shape bits are continue, attack, alternate and hold from bit 3 down
+func (a *s5bAudio) clockEnvelope() {
+	if a.envHolding {
+		return
+	}
+	a.envStep++
+	if a.envStep < 32 {
+		return
+	}
+	shape := a.registers[13]
+	if shape&0x08 == 0 {
+		// One shot shapes fall silent and stay there
+		a.envHolding = true
+		a.envDown = true
+		a.envStep = 31
+		return
+	}
+	if shape&0x01 != 0 {
+		a.envHolding = true
+		a.envStep = 31
+		if shape&0x02 != 0 {
+			a.envDown = !a.envDown
+		}
+		return
+	}
+	a.envStep = 0
+	if shape&0x02 != 0 {
+		a.envDown = !a.envDown
+	}
+}
+
+func (a *s5bAudio) envelope() uint8 {
+	if a.envDown {
+		return 31 - a.envStep
+	}
+	return a.envStep
+}
+
+func (a *s5bAudio) output() float32 {
+	var sum float32
+	mixer := a.registers[7]
+	noise := a.noiseLFSR&0x01 != 0
+	for ch := range a.toneOut {
+		tone := a.toneOut[ch] || mixer&(0x01<<ch) != 0
+		noiseOn := noise || mixer&(0x08<<ch) != 0
+		if !tone || !noiseOn {
+			continue
+		}
+		level := a.registers[8+ch]
+		if level&0x10 != 0 {
+			sum += s5bLevels[a.envelope()]
+		} else if level&0x0F != 0 {
+			sum += s5bLevels[(level&0x0F)*2+1]
+		}
+	}
+	return sum * s5bVolume
+}
+
+func (a *s5bAudio) state() []any {
+	return []any{&a.registers, &a.address, &a.prescaler, &a.toneTimer, &a.toneOut, &a.noiseTimer, &a.noiseHalf,
+		&a.noiseLFSR, &a.envTimer, &a.envStep, &a.envHolding, &a.envDown}
+}
+
+func (a *s5bAudio) reset() {
+	*a = s5bAudio{noiseLFSR: 0x0001}
+}
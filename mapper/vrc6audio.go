@@ -0,0 +1,152 @@
+package mapper
+
+// VRC6 square channel, 16 steps with a duty of 1-8 steps or a constant
+// level in digitized mode
+type vrc6Pulse struct {
+	volume  uint8
+	duty    uint8
+	mode    bool
+	period  uint16
+	enabled bool
+	timer   uint16
+	step    uint8
+}
+
+func (p *vrc6Pulse) write(reg uint16, data uint8) {
+	switch reg {
+	case 0:
+		p.mode = data&0x80 != 0
+		p.duty = (data >> 4) & 0x07
+		p.volume = data & 0x0F
+	case 1:
+		p.period = p.period&0x0F00 | uint16(data)
+	case 2:
+		p.period = p.period&0x00FF | uint16(data&0x0F)<<8
+		p.enabled = data&0x80 != 0
+		if !p.enabled {
+			p.step = 15
+		}
+	}
+}
+
+func (p *vrc6Pulse) clock(shift uint8) {
+	if !p.enabled {
+		return
+	}
+	if p.timer == 0 {
+		p.timer = p.period >> shift
+		p.step = (p.step - 1) & 0x0F
+	} else {
+		p.timer--
+	}
+}
+
+func (p *vrc6Pulse) output() uint8 {
+	if p.enabled && (p.mode || p.step <= p.duty) {
+		return p.volume
+	}
+	return 0
+}
+
+func (p *vrc6Pulse) state() []any {
+	return []any{&p.volume, &p.duty, &p.mode, &p.period, &p.enabled, &p.timer, &p.step}
+}
+
+// VRC6 sawtooth, an accumulator that adds its rate every second clock and
+// clears on the fourteenth
+type vrc6Saw struct {
+	rate    uint8
+	period  uint16
+	enabled bool
+	timer   uint16
+	step    uint8
+	acc     uint8
+}
+
+func (s *vrc6Saw) write(reg uint16, data uint8) {
+	switch reg {
+	case 0:
+		s.rate = data & 0x3F
+	case 1:
+		s.period = s.period&0x0F00 | uint16(data)
+	case 2:
+		s.period = s.period&0x00FF | uint16(data&0x0F)<<8
+		s.enabled = data&0x80 != 0
+		if !s.enabled {
+			s.step = 0
+			s.acc = 0
+		}
+	}
+}
+
+func (s *vrc6Saw) clock(shift uint8) {
+	if !s.enabled {
+		return
+	}
+	if s.timer > 0 {
+		s.timer--
+		return
+	}
+	s.timer = s.period >> shift
+	s.step++
+	if s.step == 14 {
+		s.step = 0
+		s.acc = 0
+	} else if s.step&0x01 == 0 {
+		s.acc += s.rate
+	}
+}
+
+func (s *vrc6Saw) output() uint8 {
+	return s.acc >> 3
+}
+
+func (s *vrc6Saw) state() []any {
+	return []any{&s.rate, &s.period, &s.enabled, &s.timer, &s.step, &s.acc}
+}
+
+type vrc6Audio struct {
+	pulse [2]vrc6Pulse
+	saw   vrc6Saw
+	halt  bool
+	shift uint8 // Frequency scaling from $9003
+}
+
+func (a *vrc6Audio) writeControl(data uint8) {
+	a.halt = data&0x01 != 0
+	switch {
+	case data&0x04 != 0:
+		a.shift = 8
+	case data&0x02 != 0:
+		a.shift = 4
+	default:
+		a.shift = 0
+	}
+}
+
+func (a *vrc6Audio) clock() {
+	if a.halt {
+		return
+	}
+	a.pulse[0].clock(a.shift)
+	a.pulse[1].clock(a.shift)
+	a.saw.clock(a.shift)
+}
+
+// A pulse at full volume matches an APU pulse at full volume
+func (a *vrc6Audio) output() float32 {
+	sum := a.pulse[0].output() + a.pulse[1].output() + a.saw.output()
+	return float32(sum) * 0.00996
+}
+
+func (a *vrc6Audio) state() []any {
+	s := append(a.pulse[0].state(), a.pulse[1].state()...)
+	s = append(s, a.saw.state()...)
+	return append(s, &a.halt, &a.shift)
+}
+
+func (a *vrc6Audio) reset() {
+	*a = vrc6Audio{}
+	a.pulse[0].step = 15
+	a.pulse[1].step = 15
+}
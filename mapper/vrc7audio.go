@@ -0,0 +1,289 @@
+package mapper
+
+import "math"
+
+const (
+	opllDivider = 36             // CPU cycles per OPLL sample, the chip divides 3.58 MHz by 72
+	opllRate    = 3579545.0 / 72 // Samples per second
+	opllMaxAtt  = 48.0           // dB, the envelope is silent past this
+	opllAMDepth = 4.875          // dB
+	opllVibRate = 6.4 / opllRate // Vibrato LFO cycles per sample
+	opllAMRate  = 3.7 / opllRate // Tremolo LFO cycles per sample
+	opllVibAmt  = 0.008          // Vibrato depth, about 14 cents
+	opllVolume  = 0.1            // One channel at full level against the APU mix
+)
+
+// Built-in instruments of the VRC7, instrument 0 is the custom patch in
+// registers $00-$07
+var vrc7Patches = [16][8]uint8{
+	{},
+	{0x03, 0x21, 0x05, 0x06, 0xE8, 0x81, 0x42, 0x27},
+	{0x13, 0x41, 0x14, 0x0D, 0xD8, 0xF6, 0x23, 0x12},
+	{0x11, 0x11, 0x08, 0x08, 0xFA, 0xB2, 0x20, 0x12},
+	{0x31, 0x61, 0x0C, 0x07, 0xA8, 0x64, 0x61, 0x27},
+	{0x32, 0x21, 0x1E, 0x06, 0xE1, 0x76, 0x01, 0x28},
+	{0x02, 0x01, 0x06, 0x00, 0xA3, 0xE2, 0xF4, 0xF4},
+	{0x21, 0x61, 0x1D, 0x07, 0x82, 0x81, 0x11, 0x07},
+	{0x23, 0x21, 0x22, 0x17, 0xA2, 0x72, 0x01, 0x17},
+	{0x35, 0x11, 0x25, 0x00, 0x40, 0x73, 0x72, 0x01},
+	{0xB5, 0x01, 0x0F, 0x0F, 0xA8, 0xA5, 0x51, 0x02},
+	{0x17, 0xC1, 0x24, 0x07, 0xF8, 0xF8, 0x22, 0x12},
+	{0x71, 0x23, 0x11, 0x06, 0x65, 0x74, 0x18, 0x16},
+	{0x01, 0x02, 0xD3, 0x05, 0xC9, 0x95, 0x03, 0x02},
+	{0x61, 0x63, 0x0C, 0x00, 0x94, 0xC0, 0x33, 0xF6},
+	{0x21, 0x72, 0x0D, 0x00, 0xC1, 0xD5, 0x56, 0x06},
+}
+
+var opllMultiplier = [16]float64{0.5, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 10, 12, 12, 15, 15}
+
+// Key scale attenuation in dB at 6 dB/octave, indexed by the top 4 F-number bits
+var opllKSL = [16]float64{0, 18, 24, 27.75, 30, 32.25, 33.75, 35.25, 36, 37.5, 38.25, 39, 39.75, 40.5, 41.25, 42}
+
+// KSL settings 0-3 are 0, 1.5, 3 and 6 dB/octave
+var opllKSLScale = [4]float64{0, 0.25, 0.5, 1}
+
+// Envelope stages
+const (
+	opllAttack uint8 = iota
+	opllDecay
+	opllSustain
+	opllRelease
+	opllOff
+)
+
+type opllOperator struct {
+	phase float64 // Cycles
+	env   float64 // Attenuation in dB
+	stage uint8
+	out   [2]float64 // Last two outputs, the modulator feeds them back
+}
+
+type opllChannel struct {
+	fnum       uint16
+	block      uint8
+	key        bool
+	sustain    bool
+	instrument uint8
+	volume     uint8
+	op         [2]opllOperator // Modulator, carrier
+}
+
+// YM2413 derived FM synthesizer of the VRC7, six two-operator channels
+type vrc7Audio struct {
+	custom   [8]uint8
+	channel  [6]opllChannel
+	address  uint8
+	divider  int32
+	amPhase  float64
+	vibPhase float64
+	level    float32
+	silenced bool
+}
+
+func (a *vrc7Audio) writeAddress(data uint8) {
+	a.address = data
+}
+
+func (a *vrc7Audio) writeData(data uint8) {
+	reg := a.address
+	if reg < 0x08 {
+		a.custom[reg] = data
+		return
+	}
+	ch := int(reg & 0x0F)
+	if ch > 5 {
+		return
+	}
+	c := &a.channel[ch]
+	switch reg & 0xF0 {
+	case 0x10:
+		c.fnum = c.fnum&0x0100 | uint16(data)
+	case 0x20:
+		c.fnum = c.fnum&0x00FF | uint16(data&0x01)<<8
+		c.block = (data >> 1) & 0x07
+		c.sustain = data&0x20 != 0
+		key := data&0x10 != 0
+		if key && !c.key {
+			for i := range c.op {
+				c.op[i].phase = 0
+				c.op[i].stage = opllAttack
+			}
+		} else if !key && c.key {
+			for i := range c.op {
+				if c.op[i].stage != opllOff {
+					c.op[i].stage = opllRelease
+				}
+			}
+		}
+		c.key = key
+	case 0x30:
+		c.instrument = data >> 4
+		c.volume = data & 0x0F
+	}
+}
+
+func (a *vrc7Audio) patch(c *opllChannel) *[8]uint8 {
+	if c.instrument == 0 {
+		return &a.custom
+	}
+	return &vrc7Patches[c.instrument]
+}
+
+// Rates count like the OPL: rate 1 decays the full 96 dB range in 39.28 s
+// and each step doubles the speed, the key scale rate adds quarter steps
+func opllDecayStep(rate float64) float64 {
+	if rate <= 0 {
+		return 0
+	}
+	return 96 / (39.28 / math.Exp2(min(rate, 15)-1)) / opllRate
+}
+
+func opllAttackFactor(rate float64) float64 {
+	if rate <= 0 {
+		return 0
+	}
+	if rate >= 15 {
+		return 1
+	}
+	return min(6/(2.826/math.Exp2(rate-1)*opllRate), 1)
+}
+
+func (a *vrc7Audio) envelope(c *opllChannel, o *opllOperator, p *[8]uint8, car int) {
+	sustained := p[car]&0x20 != 0
+	rks := float64(uint16(c.block)<<1 | c.fnum>>8)
+	if p[car]&0x10 == 0 {
+		rks = math.Floor(rks / 4)
+	}
+	rate := func(r uint8) float64 {
+		if r == 0 {
+			return 0
+		}
+		return float64(r) + rks/4
+	}
+	ar := p[4+car] >> 4
+	dr := p[4+car] & 0x0F
+	sl := float64(p[6+car]>>4) * 3
+	rr := p[6+car] & 0x0F
+	switch o.stage {
+	case opllAttack:
+		o.env -= o.env * opllAttackFactor(rate(ar))
+		if o.env < 0.1 {
+			o.env = 0
+			o.stage = opllDecay
+		}
+	case opllDecay:
+		o.env += opllDecayStep(rate(dr))
+		if o.env >= sl {
+			o.env = sl
+			o.stage = opllSustain
+		}
+	case opllSustain:
+		if !sustained {
+			o.env += opllDecayStep(rate(rr))
+		}
+	case opllRelease:
+		switch {
+		case c.sustain:
+			o.env += opllDecayStep(rate(5))
+		case sustained:
+			o.env += opllDecayStep(rate(rr))
+		default:
+			o.env += opllDecayStep(rate(7))
+		}
+	}
+	if o.env >= opllMaxAtt {
+		o.env = opllMaxAtt
+		if o.stage != opllAttack {
+			o.stage = opllOff
+		}
+	}
+}
+
+// Output of one operator, modulation is a phase offset in cycles
+func (a *vrc7Audio) operator(c *opllChannel, o *opllOperator, p *[8]uint8, car int, att float64, modulation float64) float64 {
+	vib := 1.0
+	if p[car]&0x40 != 0 {
+		vib += opllVibAmt * math.Sin(2*math.Pi*a.vibPhase)
+	}
+	o.phase += float64(c.fnum) * math.Exp2(float64(c.block)) / (1 << 19) * opllMultiplier[p[car]&0x0F] * vib
+	o.phase -= math.Floor(o.phase)
+
+	a.envelope(c, o, p, car)
+	att += o.env
+	ksl := opllKSL[c.fnum>>5] - 6*float64(7-c.block)
+	if ksl > 0 {
+		att += ksl * opllKSLScale[p[2+car]>>6]
+	}
+	if p[car]&0x80 != 0 {
+		att += opllAMDepth * (1 + math.Sin(2*math.Pi*a.amPhase)) / 2
+	}
+	if att >= opllMaxAtt || o.stage == opllOff {
+		return 0
+	}
+	wave := math.Sin(2 * math.Pi * (o.phase + modulation))
+	// Bits 3 and 4 of byte 3 rectify the modulator and carrier to half sines
+	if wave < 0 && p[3]&(0x08<<car) != 0 {
+		wave = 0
+	}
+	return wave * math.Pow(10, -att/20)
+}
+
+func (a *vrc7Audio) clock() {
+	a.divider--
+	if a.divider > 0 {
+		return
+	}
+	a.divider = opllDivider
+	a.amPhase += opllAMRate
+	a.amPhase -= math.Floor(a.amPhase)
+	a.vibPhase += opllVibRate
+	a.vibPhase -= math.Floor(a.vibPhase)
+
+	var sum float64
+	for i := range a.channel {
+		c := &a.channel[i]
+		p := a.patch(c)
+		mod := &c.op[0]
+		var feedback float64
+		if fb := p[3] & 0x07; fb > 0 {
+			feedback = (mod.out[0] + mod.out[1]) * math.Exp2(float64(fb)-7)
+		}
+		m := a.operator(c, mod, p, 0, float64(p[2]&0x3F)*0.75, feedback)
+		mod.out[1] = mod.out[0]
+		mod.out[0] = m
+		// Full scale modulator output swings the carrier phase by 4 cycles
+		sum += a.operator(c, &c.op[1], p, 1, float64(c.volume)*3, m*4)
+	}
+	a.level = float32(sum * opllVolume)
+}
+
+func (a *vrc7Audio) output() float32 {
+	if a.silenced {
+		return 0
+	}
+	return a.level
+}
+
+func (a *vrc7Audio) state() []any {
+	s := []any{&a.custom, &a.address, &a.divider, &a.amPhase, &a.vibPhase, &a.level, &a.silenced}
+	for i := range a.channel {
+		c := &a.channel[i]
+		s = append(s, &c.fnum, &c.block, &c.key, &c.sustain, &c.instrument, &c.volume)
+		for j := range c.op {
+			o := &c.op[j]
+			s = append(s, &o.phase, &o.env, &o.stage, &o.out)
+		}
+	}
+	return s
+}
+
+func (a *vrc7Audio) reset() {
+	*a = vrc7Audio{}
+	for i := range a.channel {
+		for j := range a.channel[i].op {
+			a.channel[i].op[j].env = opllMaxAtt
+			a.channel[i].op[j].stage = opllOff
+		}
+	}
+	a.divider = opllDivider
+}
@@ -0,0 +1,63 @@
+package mapper
+
+// IRQ counter shared by the VRC4, VRC6 and VRC7. In scanline mode a
+// prescaler divides CPU cycles by 113.667 so the 8-bit counter steps once per
+// scanline, in cycle mode it steps every CPU cycle. The IRQ fires when the
+// counter overflows, reloading it from the latch
+type vrcIRQ struct {
+	latch     uint8
+	counter   uint8
+	prescaler int32
+	enable    bool
+	enableAck bool // Enable to restore when the IRQ is acknowledged
+	cycleMode bool
+	active    bool
+}
+
+func (v *vrcIRQ) writeLatch(data uint8) {
+	v.latch = data
+}
+
+func (v *vrcIRQ) writeControl(data uint8) {
+	v.enableAck = data&0x01 != 0
+	v.enable = data&0x02 != 0
+	v.cycleMode = data&0x04 != 0
+	if v.enable {
+		v.counter = v.latch
+		v.prescaler = 341
+	}
+	v.active = false
+}
+
+func (v *vrcIRQ) acknowledge() {
+	v.active = false
+	v.enable = v.enableAck
+}
+
+func (v *vrcIRQ) clock() {
+	if !v.enable {
+		return
+	}
+	if !v.cycleMode {
+		v.prescaler -= 3
+		if v.prescaler > 0 {
+			return
+		}
+		v.prescaler += 341
+	}
+	if v.counter == 0xFF {
+		v.counter = v.latch
+		v.active = true
+	} else {
+		v.counter++
+	}
+}
+
+// Pointers to the fields kept in save states
+func (v *vrcIRQ) state() []any {
+	return []any{&v.latch, &v.counter, &v.prescaler, &v.enable, &v.enableAck, &v.cycleMode, &v.active}
+}
+
+func (v *vrcIRQ) reset() {
+	*v = vrcIRQ{}
+}
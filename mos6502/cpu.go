@@ -2,6 +2,7 @@ package mos6502
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 )
 
@@ -40,7 +41,9 @@ type CPU struct {
 	addrRel uint16
 	opcode  uint8
 	cycles  uint8
+	total   uint64 // Cycles since reset
 	bus     Bus
+	trace   io.Writer
 	lookup  [16 * 16]Instruction
 }
 
@@ -57,7 +60,9 @@ func NewCPU() *CPU {
 		addrRel: 0x0000,
 		opcode:  0x00,
 		cycles:  0x00,
+		total:   0,
 		bus:     nil,
+		trace:   nil,
 	}
 	cpu.lookup = [16 * 16]Instruction{
 		{"BRK", cpu.BRK, cpu.IMM, 7}, {"ORA", cpu.ORA, cpu.IZX, 6}, {"???", cpu.XXX, cpu.IMP, 2}, {"???", cpu.XXX, cpu.IMP, 8}, {"???", cpu.NOP, cpu.IMP, 3}, {"ORA", cpu.ORA, cpu.ZP0, 3}, {"ASL", cpu.ASL, cpu.ZP0, 5}, {"???", cpu.XXX, cpu.IMP, 5}, {"PHP", cpu.PHP, cpu.IMP, 3}, {"ORA", cpu.ORA, cpu.IMM, 2}, {"ASL", cpu.ASL, cpu.IMP, 2}, {"???", cpu.XXX, cpu.IMP, 2}, {"???", cpu.NOP, cpu.IMP, 4}, {"ORA", cpu.ORA, cpu.ABS, 4}, {"ASL", cpu.ASL, cpu.ABS, 6}, {"???", cpu.XXX, cpu.IMP, 6},
@@ -84,6 +89,17 @@ func (cpu *CPU) ConnectBus(bus Bus) {
 	cpu.bus = bus
 }
 
+func (cpu *CPU) SetTrace(w io.Writer) {
+	cpu.trace = w
+}
+
+// One line per instruction in the style of the nestest log
+func (cpu *CPU) writeTrace() {
+	opcode := cpu.bus.Read(cpu.pc, true)
+	fmt.Fprintf(cpu.trace, "%04X  %02X  %s  A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d\n",
+		cpu.pc, opcode, cpu.lookup[opcode].Name, cpu.a, cpu.x, cpu.y, cpu.status, cpu.sp, cpu.total)
+}
+
 func (cpu *CPU) getFlag(flag uint8) uint8 {
 	return cpu.status & ^flag
 }
@@ -2,6 +2,9 @@ package mos6502
 
 func (cpu *CPU) Clock() {
 	if cpu.cycles == 0 {
+		if cpu.trace != nil {
+			cpu.writeTrace()
+		}
 		cpu.opcode = cpu.read(cpu.pc)
 		cpu.setFlag(U, true)
 		cpu.pc++
@@ -12,6 +15,7 @@ func (cpu *CPU) Clock() {
 		cpu.setFlag(U, true)
 	}
 	cpu.cycles--
+	cpu.total++
 }
 
 func (cpu *CPU) Reset() {
@@ -28,6 +32,7 @@ func (cpu *CPU) Reset() {
 	cpu.addrAbs = 0x0000
 	cpu.fetched = 0x00
 	cpu.cycles = 8
+	cpu.total = 0
 }
 
 func (cpu *CPU) IRQ() {
@@ -58,7 +63,7 @@ func (cpu *CPU) NMI() {
 	cpu.setFlag(I, true)
 	cpu.write(0x0100+uint16(cpu.sp), cpu.status)
 	cpu.sp--
-	cpu.addrAbs = 0xFFFE
+	cpu.addrAbs = 0xFFFA
 	low := uint16(cpu.read(cpu.addrAbs + 0))
 	high := uint16(cpu.read(cpu.addrAbs + 1))
 	cpu.pc = (high << 8) | low
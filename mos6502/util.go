@@ -12,6 +12,10 @@ func (cpu *CPU) GetY() uint8 {
 	return cpu.y
 }
 
+func (cpu *CPU) SetPC(pc uint16) {
+	cpu.pc = pc
+}
+
 func (cpu *CPU) GetPC() uint16 {
 	return cpu.pc
 }
@@ -23,3 +27,7 @@ func (cpu *CPU) GetSP() uint8 {
 func (cpu *CPU) GetStatus() uint8 {
 	return cpu.status
 }
+
+func (cpu *CPU) Complete() bool {
+	return cpu.cycles == 0
+}
@@ -0,0 +1,136 @@
+package rp2C02
+
+type background struct {
+	nextTileID     uint8
+	nextTileAttrib uint8
+	nextTileLSB    uint8
+	nextTileMSB    uint8
+	patternLow     uint16
+	patternHigh    uint16
+	attribLow      uint16
+	attribHigh     uint16
+}
+
+func (ppu *PPU) fetchNameTable() {
+	ppu.bg.nextTileID = ppu.Read(0x2000|(ppu.vramAddr.Reg&0x0FFF), false)
+}
+
+func (ppu *PPU) fetchAttribute() {
+	v := ppu.vramAddr
+	addr := 0x23C0 | (uint16(v.NameTableY) << 11) | (uint16(v.NameTableX) << 10) | (uint16(v.CoarseY>>2) << 3) | uint16(v.CoarseX>>2)
+	attrib := ppu.Read(addr, false)
+	if v.CoarseY&0x02 != 0 {
+		attrib >>= 4
+	}
+	if v.CoarseX&0x02 != 0 {
+		attrib >>= 2
+	}
+	ppu.bg.nextTileAttrib = attrib & 0x03
+}
+
+func (ppu *PPU) patternAddress() uint16 {
+	return (uint16(ppu.control.PatternBackground) << 12) + (uint16(ppu.bg.nextTileID) << 4) + uint16(ppu.vramAddr.FineY)
+}
+
+func (ppu *PPU) fetchPatternLow() {
+	ppu.bg.nextTileLSB = ppu.Read(ppu.patternAddress()+0, false)
+}
+
+func (ppu *PPU) fetchPatternHigh() {
+	ppu.bg.nextTileMSB = ppu.Read(ppu.patternAddress()+8, false)
+}
+
+func (ppu *PPU) incrementScrollX() {
+	if !ppu.renderingEnabled() {
+		return
+	}
+	if ppu.vramAddr.CoarseX == 31 {
+		ppu.vramAddr.CoarseX = 0
+		ppu.vramAddr.NameTableX = ^ppu.vramAddr.NameTableX & 0x01
+	} else {
+		ppu.vramAddr.CoarseX++
+	}
+	ppu.vramAddr.Update()
+}
+
+func (ppu *PPU) incrementScrollY() {
+	if !ppu.renderingEnabled() {
+		return
+	}
+	if ppu.vramAddr.FineY < 7 {
+		ppu.vramAddr.FineY++
+	} else {
+		ppu.vramAddr.FineY = 0
+		switch ppu.vramAddr.CoarseY {
+		case 29:
+			ppu.vramAddr.CoarseY = 0
+			ppu.vramAddr.NameTableY = ^ppu.vramAddr.NameTableY & 0x01
+		case 31:
+			ppu.vramAddr.CoarseY = 0
+		default:
+			ppu.vramAddr.CoarseY++
+		}
+	}
+	ppu.vramAddr.Update()
+}
+
+func (ppu *PPU) transferAddressX() {
+	if !ppu.renderingEnabled() {
+		return
+	}
+	ppu.vramAddr.NameTableX = ppu.tramAddr.NameTableX
+	ppu.vramAddr.CoarseX = ppu.tramAddr.CoarseX
+	ppu.vramAddr.Update()
+}
+
+func (ppu *PPU) transferAddressY() {
+	if !ppu.renderingEnabled() {
+		return
+	}
+	ppu.vramAddr.FineY = ppu.tramAddr.FineY
+	ppu.vramAddr.NameTableY = ppu.tramAddr.NameTableY
+	ppu.vramAddr.CoarseY = ppu.tramAddr.CoarseY
+	ppu.vramAddr.Update()
+}
+
+func (ppu *PPU) loadBackgroundShifters() {
+	ppu.bg.patternLow = (ppu.bg.patternLow & 0xFF00) | uint16(ppu.bg.nextTileLSB)
+	ppu.bg.patternHigh = (ppu.bg.patternHigh & 0xFF00) | uint16(ppu.bg.nextTileMSB)
+	if ppu.bg.nextTileAttrib&0x01 != 0 {
+		ppu.bg.attribLow = (ppu.bg.attribLow & 0xFF00) | 0x00FF
+	} else {
+		ppu.bg.attribLow = ppu.bg.attribLow & 0xFF00
+	}
+	if ppu.bg.nextTileAttrib&0x02 != 0 {
+		ppu.bg.attribHigh = (ppu.bg.attribHigh & 0xFF00) | 0x00FF
+	} else {
+		ppu.bg.attribHigh = ppu.bg.attribHigh & 0xFF00
+	}
+}
+
+func (ppu *PPU) updateShifters() {
+	if ppu.mask.RenderBackground != 0 {
+		ppu.bg.patternLow <<= 1
+		ppu.bg.patternHigh <<= 1
+		ppu.bg.attribLow <<= 1
+		ppu.bg.attribHigh <<= 1
+	}
+}
+
+func (ppu *PPU) backgroundPixel() (uint8, uint8) {
+	mux := uint16(0x8000) >> ppu.fineX
+	var pixel, palette uint8 = 0x00, 0x00
+	if ppu.bg.patternLow&mux != 0 {
+		pixel |= 0x01
+	}
+	if ppu.bg.patternHigh&mux != 0 {
+		pixel |= 0x02
+	}
+	if ppu.bg.attribLow&mux != 0 {
+		palette |= 0x01
+	}
+	if ppu.bg.attribHigh&mux != 0 {
+		palette |= 0x02
+	}
+	return pixel, palette
+}
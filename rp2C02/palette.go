@@ -0,0 +1,75 @@
+package rp2C02
+
+import "image/color"
+
+// Colour generators found in NES and Vs. System PPUs
+const (
+	PaletteRP2C02 uint8 = iota // Composite NTSC
+	PaletteRP2C03              // RGB, also used by the RC2C05 variants
+	PaletteRP2C04_0001
+	PaletteRP2C04_0002
+	PaletteRP2C04_0003
+	PaletteRP2C04_0004
+)
+
+// 9-bit RGB colours of the RP2C03, one octal digit per channel
+var rgbPalette = [64]uint16{
+	0333, 0014, 0006, 0326, 0403, 0503, 0510, 0420, 0320, 0120, 0031, 0040, 0022, 0000, 0000, 0000,
+	0555, 0036, 0027, 0407, 0507, 0704, 0700, 0630, 0430, 0140, 0040, 0053, 0044, 0000, 0000, 0000,
+	0777, 0357, 0447, 0637, 0707, 0737, 0740, 0750, 0660, 0360, 0070, 0276, 0077, 0000, 0000, 0000,
+	0777, 0567, 0657, 0757, 0747, 0755, 0764, 0772, 0773, 0572, 0473, 0276, 0467, 0000, 0000, 0000,
+}
+
+// The RP2C04 variants scramble the RP2C03 colours as a copy protection,
+// each table maps a palette index to the RP2C03 colour it shows
+var rp2C04Lookup = [4][64]uint8{
+	{
+		0x35, 0x23, 0x16, 0x22, 0x1C, 0x09, 0x1D, 0x15, 0x20, 0x00, 0x27, 0x05, 0x04, 0x28, 0x08, 0x20,
+		0x21, 0x3E, 0x1F, 0x29, 0x3C, 0x32, 0x36, 0x12, 0x3F, 0x2B, 0x2E, 0x1E, 0x3D, 0x2D, 0x24, 0x01,
+		0x0E, 0x31, 0x33, 0x2A, 0x2C, 0x0C, 0x1B, 0x14, 0x2E, 0x07, 0x34, 0x06, 0x13, 0x02, 0x26, 0x2E,
+		0x2E, 0x19, 0x10, 0x0A, 0x39, 0x03, 0x37, 0x17, 0x0F, 0x11, 0x0B, 0x0D, 0x38, 0x25, 0x18, 0x3A,
+	},
+	{
+		0x2E, 0x27, 0x18, 0x39, 0x3A, 0x25, 0x1C, 0x31, 0x16, 0x13, 0x38, 0x34, 0x20, 0x23, 0x3C, 0x0B,
+		0x0F, 0x21, 0x06, 0x3D, 0x1B, 0x29, 0x1E, 0x22, 0x1D, 0x24, 0x0E, 0x2B, 0x32, 0x08, 0x2E, 0x03,
+		0x04, 0x36, 0x26, 0x33, 0x11, 0x1F, 0x10, 0x02, 0x14, 0x3F, 0x00, 0x09, 0x12, 0x2E, 0x28, 0x20,
+		0x3E, 0x0D, 0x2A, 0x17, 0x0C, 0x01, 0x15, 0x19, 0x2E, 0x2C, 0x07, 0x37, 0x35, 0x05, 0x0A, 0x2D,
+	},
+	{
+		0x14, 0x25, 0x3A, 0x10, 0x0B, 0x20, 0x31, 0x09, 0x01, 0x2E, 0x36, 0x08, 0x15, 0x3D, 0x3E, 0x3C,
+		0x22, 0x1C, 0x05, 0x12, 0x19, 0x18, 0x17, 0x1B, 0x00, 0x03, 0x2E, 0x02, 0x16, 0x06, 0x34, 0x35,
+		0x23, 0x0F, 0x0E, 0x37, 0x0D, 0x27, 0x26, 0x20, 0x29, 0x04, 0x21, 0x24, 0x11, 0x2D, 0x2E, 0x1F,
+		0x2C, 0x1E, 0x39, 0x33, 0x07, 0x2A, 0x28, 0x1D, 0x0A, 0x2E, 0x32, 0x38, 0x13, 0x2B, 0x3F, 0x0C,
+	},
+	{
+		0x18, 0x03, 0x1C, 0x28, 0x2E, 0x35, 0x01, 0x17, 0x10, 0x1F, 0x2A, 0x0E, 0x36, 0x37, 0x0B, 0x39,
+		0x25, 0x1E, 0x12, 0x34, 0x2E, 0x1D, 0x06, 0x26, 0x3E, 0x1B, 0x22, 0x19, 0x04, 0x2E, 0x3A, 0x21,
+		0x05, 0x0A, 0x07, 0x02, 0x13, 0x14, 0x00, 0x15, 0x0C, 0x3D, 0x11, 0x0F, 0x0D, 0x38, 0x2D, 0x24,
+		0x33, 0x20, 0x08, 0x16, 0x3F, 0x2B, 0x20, 0x3C, 0x2E, 0x27, 0x23, 0x31, 0x29, 0x32, 0x2C, 0x09,
+	},
+}
+
+func rgbColor(c uint16) color.RGBA {
+	return color.RGBA{
+		R: uint8((c >> 6 & 7) * 255 / 7),
+		G: uint8((c >> 3 & 7) * 255 / 7),
+		B: uint8((c & 7) * 255 / 7),
+		A: 255,
+	}
+}
+
+// Selects the colour generator used for the output
+func (ppu *PPU) SetPalette(palette uint8) {
+	switch palette {
+	case PaletteRP2C03:
+		for i, c := range rgbPalette {
+			ppu.palScreen[i] = rgbColor(c)
+		}
+	case PaletteRP2C04_0001, PaletteRP2C04_0002, PaletteRP2C04_0003, PaletteRP2C04_0004:
+		for i, c := range rp2C04Lookup[palette-PaletteRP2C04_0001] {
+			ppu.palScreen[i] = rgbColor(rgbPalette[c])
+		}
+	default:
+		ppu.palScreen = ppu.palNES
+	}
+}
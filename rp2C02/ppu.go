@@ -1,14 +1,15 @@
 package rp2C02
 
 import (
-	"math/rand"
+	"image"
+	"image/color"
 
 	"github.com/laranc/emuNES/cartridge"
-	"github.com/veandco/go-sdl2/sdl"
+	"github.com/laranc/emuNES/timing"
 )
 
 const (
-	ResX  = 320
+	ResX  = 256
 	ResY  = 240
 	Scale = 4
 )
@@ -17,20 +18,32 @@ type PPU struct {
 	nameTable       [2][1024]uint8
 	paletteTable    [32]uint8
 	rom             *cartridge.ROM
-	palScreen       [64]sdl.Color
-	sprScreen       *sdl.Surface
-	sprNameTable    [2]*sdl.Surface
-	sprPatternTable [2]*sdl.Surface
+	palScreen       [64]color.RGBA
+	palNES          [64]color.RGBA // RP2C02 colours, kept for SetPalette
+	sprScreen       *image.RGBA
+	sprNameTable    [2]*image.RGBA
+	sprPatternTable [2]*image.RGBA
 	frameComplete   bool
-	scanLine        uint16
+	oddFrame        bool
+	region          timing.Region
+	scanLine        int16
 	cycle           uint16
 	status          StatusRegister
 	mask            MaskRegister
 	control         ControlRegister
+	vramAddr        LoopyRegister
+	tramAddr        LoopyRegister
+	fineX           uint8
 	addressLatch    uint8
 	dataBuffer      uint8
-	address         uint16
-	renderer        *sdl.Renderer
+	bg              background
+	oam             [256]uint8
+	oamAddr         uint8
+	secondaryOAM    [32]uint8
+	sprites         [8]sprite
+	spriteCount     uint8
+	spriteZero      bool
+	suppressVBlank  bool
 }
 
 func NewPPU() *PPU {
@@ -38,90 +51,98 @@ func NewPPU() *PPU {
 		nameTable:       [2][1024]uint8{},
 		paletteTable:    [32]uint8{},
 		rom:             nil,
-		palScreen:       [64]sdl.Color{},
-		sprScreen:       nil,                       // W: 256 H: 240
-		sprNameTable:    [2]*sdl.Surface{nil, nil}, // W: 256 H: 240
-		sprPatternTable: [2]*sdl.Surface{nil, nil}, // W: 128 H: 128
+		palScreen:       [64]color.RGBA{},
+		palNES:          [64]color.RGBA{},
+		sprScreen:       image.NewRGBA(image.Rect(0, 0, ResX, ResY)),
+		sprNameTable:    [2]*image.RGBA{image.NewRGBA(image.Rect(0, 0, 256, 240)), image.NewRGBA(image.Rect(0, 0, 256, 240))},
+		sprPatternTable: [2]*image.RGBA{image.NewRGBA(image.Rect(0, 0, 128, 128)), image.NewRGBA(image.Rect(0, 0, 128, 128))},
 		frameComplete:   false,
+		oddFrame:        false,
+		region:          timing.NTSC,
 		scanLine:        0,
 		cycle:           0,
 		status:          MakeStatusRegister(),
 		mask:            MakeMaskRegister(),
 		control:         MakeControlRegister(),
+		vramAddr:        MakeLoopyRegister(),
+		tramAddr:        MakeLoopyRegister(),
+		fineX:           0x00,
 		addressLatch:    0,
 		dataBuffer:      0x00,
-		address:         0x0000,
-		renderer:        nil,
+		bg:              background{},
+		oam:             [256]uint8{},
+		oamAddr:         0x00,
+		secondaryOAM:    [32]uint8{},
+		sprites:         [8]sprite{},
+		spriteCount:     0,
+		spriteZero:      false,
+		suppressVBlank:  false,
 	}
-	ppu.sprScreen, _ = sdl.CreateRGBSurface(0, 256, 240, 0, 0, 0, 0, 0)
-	ppu.sprNameTable[0], _ = sdl.CreateRGBSurface(0, 256, 240, 0, 0, 0, 0, 0)
-	ppu.sprNameTable[1], _ = sdl.CreateRGBSurface(0, 256, 240, 0, 0, 0, 0, 0)
-	ppu.sprPatternTable[0], _ = sdl.CreateRGBSurface(0, 128, 128, 0, 0, 0, 0, 0)
-	ppu.sprPatternTable[1], _ = sdl.CreateRGBSurface(0, 128, 128, 0, 0, 0, 0, 0)
-	ppu.palScreen[0x00] = sdl.Color{R: 84, G: 84, B: 84, A: 255}
-	ppu.palScreen[0x01] = sdl.Color{R: 0, G: 30, B: 116, A: 255}
-	ppu.palScreen[0x02] = sdl.Color{R: 8, G: 16, B: 144, A: 255}
-	ppu.palScreen[0x03] = sdl.Color{R: 48, G: 0, B: 136, A: 255}
-	ppu.palScreen[0x04] = sdl.Color{R: 68, G: 0, B: 100, A: 255}
-	ppu.palScreen[0x05] = sdl.Color{R: 92, G: 0, B: 48, A: 255}
-	ppu.palScreen[0x06] = sdl.Color{R: 84, G: 4, B: 0, A: 255}
-	ppu.palScreen[0x07] = sdl.Color{R: 60, G: 24, B: 0, A: 255}
-	ppu.palScreen[0x08] = sdl.Color{R: 32, G: 42, B: 0, A: 255}
-	ppu.palScreen[0x09] = sdl.Color{R: 8, G: 58, B: 0, A: 255}
-	ppu.palScreen[0x0A] = sdl.Color{R: 0, G: 64, B: 0, A: 255}
-	ppu.palScreen[0x0B] = sdl.Color{R: 0, G: 60, B: 0, A: 255}
-	ppu.palScreen[0x0C] = sdl.Color{R: 0, G: 50, B: 60, A: 255}
-	ppu.palScreen[0x0D] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x0E] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x0F] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x10] = sdl.Color{R: 152, G: 150, B: 152, A: 255}
-	ppu.palScreen[0x11] = sdl.Color{R: 8, G: 76, B: 196, A: 255}
-	ppu.palScreen[0x12] = sdl.Color{R: 48, G: 50, B: 236, A: 255}
-	ppu.palScreen[0x13] = sdl.Color{R: 92, G: 30, B: 228, A: 255}
-	ppu.palScreen[0x14] = sdl.Color{R: 136, G: 20, B: 176, A: 255}
-	ppu.palScreen[0x15] = sdl.Color{R: 160, G: 20, B: 100, A: 255}
-	ppu.palScreen[0x16] = sdl.Color{R: 152, G: 34, B: 32, A: 255}
-	ppu.palScreen[0x17] = sdl.Color{R: 120, G: 60, B: 0, A: 255}
-	ppu.palScreen[0x18] = sdl.Color{R: 84, G: 90, B: 0, A: 255}
-	ppu.palScreen[0x19] = sdl.Color{R: 40, G: 114, B: 0, A: 255}
-	ppu.palScreen[0x1A] = sdl.Color{R: 8, G: 124, B: 0, A: 255}
-	ppu.palScreen[0x1B] = sdl.Color{R: 0, G: 118, B: 40, A: 255}
-	ppu.palScreen[0x1C] = sdl.Color{R: 0, G: 102, B: 120, A: 255}
-	ppu.palScreen[0x1D] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x1E] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x1F] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x20] = sdl.Color{R: 236, G: 238, B: 236, A: 255}
-	ppu.palScreen[0x21] = sdl.Color{R: 76, G: 154, B: 236, A: 255}
-	ppu.palScreen[0x22] = sdl.Color{R: 120, G: 124, B: 236, A: 255}
-	ppu.palScreen[0x23] = sdl.Color{R: 176, G: 98, B: 236, A: 255}
-	ppu.palScreen[0x24] = sdl.Color{R: 228, G: 84, B: 236, A: 255}
-	ppu.palScreen[0x25] = sdl.Color{R: 236, G: 88, B: 180, A: 255}
-	ppu.palScreen[0x26] = sdl.Color{R: 236, G: 106, B: 100, A: 255}
-	ppu.palScreen[0x27] = sdl.Color{R: 212, G: 136, B: 32, A: 255}
-	ppu.palScreen[0x28] = sdl.Color{R: 160, G: 170, B: 0, A: 255}
-	ppu.palScreen[0x29] = sdl.Color{R: 116, G: 196, B: 0, A: 255}
-	ppu.palScreen[0x2A] = sdl.Color{R: 76, G: 208, B: 32, A: 255}
-	ppu.palScreen[0x2B] = sdl.Color{R: 56, G: 204, B: 108, A: 255}
-	ppu.palScreen[0x2C] = sdl.Color{R: 56, G: 180, B: 204, A: 255}
-	ppu.palScreen[0x2D] = sdl.Color{R: 60, G: 60, B: 60, A: 255}
-	ppu.palScreen[0x2E] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x2F] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x30] = sdl.Color{R: 236, G: 238, B: 236, A: 255}
-	ppu.palScreen[0x31] = sdl.Color{R: 168, G: 204, B: 236, A: 255}
-	ppu.palScreen[0x32] = sdl.Color{R: 188, G: 188, B: 236, A: 255}
-	ppu.palScreen[0x33] = sdl.Color{R: 212, G: 178, B: 236, A: 255}
-	ppu.palScreen[0x34] = sdl.Color{R: 236, G: 174, B: 236, A: 255}
-	ppu.palScreen[0x35] = sdl.Color{R: 236, G: 174, B: 212, A: 255}
-	ppu.palScreen[0x36] = sdl.Color{R: 236, G: 180, B: 176, A: 255}
-	ppu.palScreen[0x37] = sdl.Color{R: 228, G: 196, B: 144, A: 255}
-	ppu.palScreen[0x38] = sdl.Color{R: 204, G: 210, B: 120, A: 255}
-	ppu.palScreen[0x39] = sdl.Color{R: 180, G: 222, B: 120, A: 255}
-	ppu.palScreen[0x3A] = sdl.Color{R: 168, G: 226, B: 144, A: 255}
-	ppu.palScreen[0x3B] = sdl.Color{R: 152, G: 226, B: 180, A: 255}
-	ppu.palScreen[0x3C] = sdl.Color{R: 160, G: 214, B: 228, A: 255}
-	ppu.palScreen[0x3D] = sdl.Color{R: 160, G: 162, B: 160, A: 255}
-	ppu.palScreen[0x3E] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
-	ppu.palScreen[0x3F] = sdl.Color{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x00] = color.RGBA{R: 84, G: 84, B: 84, A: 255}
+	ppu.palScreen[0x01] = color.RGBA{R: 0, G: 30, B: 116, A: 255}
+	ppu.palScreen[0x02] = color.RGBA{R: 8, G: 16, B: 144, A: 255}
+	ppu.palScreen[0x03] = color.RGBA{R: 48, G: 0, B: 136, A: 255}
+	ppu.palScreen[0x04] = color.RGBA{R: 68, G: 0, B: 100, A: 255}
+	ppu.palScreen[0x05] = color.RGBA{R: 92, G: 0, B: 48, A: 255}
+	ppu.palScreen[0x06] = color.RGBA{R: 84, G: 4, B: 0, A: 255}
+	ppu.palScreen[0x07] = color.RGBA{R: 60, G: 24, B: 0, A: 255}
+	ppu.palScreen[0x08] = color.RGBA{R: 32, G: 42, B: 0, A: 255}
+	ppu.palScreen[0x09] = color.RGBA{R: 8, G: 58, B: 0, A: 255}
+	ppu.palScreen[0x0A] = color.RGBA{R: 0, G: 64, B: 0, A: 255}
+	ppu.palScreen[0x0B] = color.RGBA{R: 0, G: 60, B: 0, A: 255}
+	ppu.palScreen[0x0C] = color.RGBA{R: 0, G: 50, B: 60, A: 255}
+	ppu.palScreen[0x0D] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x0E] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x0F] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x10] = color.RGBA{R: 152, G: 150, B: 152, A: 255}
+	ppu.palScreen[0x11] = color.RGBA{R: 8, G: 76, B: 196, A: 255}
+	ppu.palScreen[0x12] = color.RGBA{R: 48, G: 50, B: 236, A: 255}
+	ppu.palScreen[0x13] = color.RGBA{R: 92, G: 30, B: 228, A: 255}
+	ppu.palScreen[0x14] = color.RGBA{R: 136, G: 20, B: 176, A: 255}
+	ppu.palScreen[0x15] = color.RGBA{R: 160, G: 20, B: 100, A: 255}
+	ppu.palScreen[0x16] = color.RGBA{R: 152, G: 34, B: 32, A: 255}
+	ppu.palScreen[0x17] = color.RGBA{R: 120, G: 60, B: 0, A: 255}
+	ppu.palScreen[0x18] = color.RGBA{R: 84, G: 90, B: 0, A: 255}
+	ppu.palScreen[0x19] = color.RGBA{R: 40, G: 114, B: 0, A: 255}
+	ppu.palScreen[0x1A] = color.RGBA{R: 8, G: 124, B: 0, A: 255}
+	ppu.palScreen[0x1B] = color.RGBA{R: 0, G: 118, B: 40, A: 255}
+	ppu.palScreen[0x1C] = color.RGBA{R: 0, G: 102, B: 120, A: 255}
+	ppu.palScreen[0x1D] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x1E] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x1F] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x20] = color.RGBA{R: 236, G: 238, B: 236, A: 255}
+	ppu.palScreen[0x21] = color.RGBA{R: 76, G: 154, B: 236, A: 255}
+	ppu.palScreen[0x22] = color.RGBA{R: 120, G: 124, B: 236, A: 255}
+	ppu.palScreen[0x23] = color.RGBA{R: 176, G: 98, B: 236, A: 255}
+	ppu.palScreen[0x24] = color.RGBA{R: 228, G: 84, B: 236, A: 255}
+	ppu.palScreen[0x25] = color.RGBA{R: 236, G: 88, B: 180, A: 255}
+	ppu.palScreen[0x26] = color.RGBA{R: 236, G: 106, B: 100, A: 255}
+	ppu.palScreen[0x27] = color.RGBA{R: 212, G: 136, B: 32, A: 255}
+	ppu.palScreen[0x28] = color.RGBA{R: 160, G: 170, B: 0, A: 255}
+	ppu.palScreen[0x29] = color.RGBA{R: 116, G: 196, B: 0, A: 255}
+	ppu.palScreen[0x2A] = color.RGBA{R: 76, G: 208, B: 32, A: 255}
+	ppu.palScreen[0x2B] = color.RGBA{R: 56, G: 204, B: 108, A: 255}
+	ppu.palScreen[0x2C] = color.RGBA{R: 56, G: 180, B: 204, A: 255}
+	ppu.palScreen[0x2D] = color.RGBA{R: 60, G: 60, B: 60, A: 255}
+	ppu.palScreen[0x2E] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x2F] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x30] = color.RGBA{R: 236, G: 238, B: 236, A: 255}
+	ppu.palScreen[0x31] = color.RGBA{R: 168, G: 204, B: 236, A: 255}
+	ppu.palScreen[0x32] = color.RGBA{R: 188, G: 188, B: 236, A: 255}
+	ppu.palScreen[0x33] = color.RGBA{R: 212, G: 178, B: 236, A: 255}
+	ppu.palScreen[0x34] = color.RGBA{R: 236, G: 174, B: 236, A: 255}
+	ppu.palScreen[0x35] = color.RGBA{R: 236, G: 174, B: 212, A: 255}
+	ppu.palScreen[0x36] = color.RGBA{R: 236, G: 180, B: 176, A: 255}
+	ppu.palScreen[0x37] = color.RGBA{R: 228, G: 196, B: 144, A: 255}
+	ppu.palScreen[0x38] = color.RGBA{R: 204, G: 210, B: 120, A: 255}
+	ppu.palScreen[0x39] = color.RGBA{R: 180, G: 222, B: 120, A: 255}
+	ppu.palScreen[0x3A] = color.RGBA{R: 168, G: 226, B: 144, A: 255}
+	ppu.palScreen[0x3B] = color.RGBA{R: 152, G: 226, B: 180, A: 255}
+	ppu.palScreen[0x3C] = color.RGBA{R: 160, G: 214, B: 228, A: 255}
+	ppu.palScreen[0x3D] = color.RGBA{R: 160, G: 162, B: 160, A: 255}
+	ppu.palScreen[0x3E] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palScreen[0x3F] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	ppu.palNES = ppu.palScreen
 
 	return ppu
 }
@@ -129,34 +150,14 @@ func NewPPU() *PPU {
 func (ppu *PPU) Read(addr uint16, readOnly bool) uint8 {
 	var data uint8 = 0x00
 	addr &= 0x3FFF
+	if !readOnly {
+		ppu.rom.PPUAddressSeen(addr)
+	}
 	if ppu.rom.PPURead(addr, &data) {
 		// Read from the rom or pass and read from PPU memory
 	} else if addr >= 0x2000 && addr <= 0x3EFF {
-		addr &= 0x0FFF
-		switch ppu.rom.GetMirror() {
-		case cartridge.MirrorVertical:
-			if addr <= 0x03FF {
-				data = ppu.nameTable[0][addr&0x03FF]
-			} else if addr >= 0x0400 && addr <= 0x07FF {
-				data = ppu.nameTable[1][addr&0x03FF]
-			} else if addr >= 0x0800 && addr <= 0x0BFF {
-				data = ppu.nameTable[0][addr&0x03FF]
-			} else if addr >= 0x0C00 && addr <= 0x0FFF {
-				data = ppu.nameTable[1][addr&0x03FF]
-			}
-		case cartridge.MirrorHorizontal:
-			if addr <= 0x03FF {
-				data = ppu.nameTable[0][addr&0x03FF]
-			} else if addr >= 0x0400 && addr <= 0x07FF {
-				data = ppu.nameTable[0][addr&0x03FF]
-			} else if addr >= 0x0800 && addr <= 0x0BFF {
-				data = ppu.nameTable[1][addr&0x03FF]
-			} else if addr >= 0x0C00 && addr <= 0x0FFF {
-				data = ppu.nameTable[1][addr&0x03FF]
-			}
-		default:
-			break
-		}
+		table, offset := ppu.mirrorAddress(addr)
+		data = ppu.nameTable[table][offset]
 	} else if addr >= 0x3F00 && addr <= 0x3FFF {
 		addr &= 0x001F
 		switch addr {
@@ -182,34 +183,12 @@ func (ppu *PPU) Read(addr uint16, readOnly bool) uint8 {
 
 func (ppu *PPU) Write(addr uint16, data uint8) {
 	addr &= 0x3FFF
+	ppu.rom.PPUAddressSeen(addr)
 	if ppu.rom.PPUWrite(addr, data) {
 		// Write to the ROM or pass and write to PPU memory
 	} else if addr >= 0x2000 && addr <= 0x3EFF {
-		addr &= 0x0FFF
-		switch ppu.rom.GetMirror() {
-		case cartridge.MirrorVertical:
-			if addr <= 0x03FF {
-				ppu.nameTable[0][addr&0x03FF] = data
-			} else if addr >= 0x0400 && addr <= 0x07FF {
-				ppu.nameTable[1][addr&0x03FF] = data
-			} else if addr >= 0x0800 && addr <= 0x0BFF {
-				ppu.nameTable[0][addr&0x03FF] = data
-			} else if addr >= 0x0C00 && addr <= 0x0FFF {
-				ppu.nameTable[1][addr&0x03FF] = data
-			}
-		case cartridge.MirrorHorizontal:
-			if addr <= 0x03FF {
-				ppu.nameTable[0][addr&0x03FF] = data
-			} else if addr >= 0x0400 && addr <= 0x07FF {
-				ppu.nameTable[0][addr&0x03FF] = data
-			} else if addr >= 0x0800 && addr <= 0x0BFF {
-				ppu.nameTable[1][addr&0x03FF] = data
-			} else if addr >= 0x0C00 && addr <= 0x0FFF {
-				ppu.nameTable[1][addr&0x03FF] = data
-			}
-		default:
-			break
-		}
+		table, offset := ppu.mirrorAddress(addr)
+		ppu.nameTable[table][offset] = data
 	} else if addr >= 0x3F00 && addr <= 0x3FFF {
 		addr &= 0x001F
 		switch addr {
@@ -228,6 +207,25 @@ func (ppu *PPU) Write(addr uint16, data uint8) {
 	}
 }
 
+// Maps a nametable address onto one of the two internal 1 KB tables, four
+// screen carts answer $2000-$2FFF themselves and never reach here
+func (ppu *PPU) mirrorAddress(addr uint16) (uint16, uint16) {
+	table := (addr & 0x0FFF) >> 10
+	switch ppu.rom.GetMirror() {
+	case cartridge.MirrorVertical:
+		table &= 0x01
+	case cartridge.MirrorHorizontal:
+		table >>= 1
+	case cartridge.MirrorOnescreenLow:
+		table = 0
+	case cartridge.MirrorOnescreenHigh:
+		table = 1
+	default:
+		table &= 0x01
+	}
+	return table, addr & 0x03FF
+}
+
 func (ppu *PPU) BusRead(addr uint16, readOnly bool) uint8 {
 	var data uint8 = 0x00
 
@@ -237,8 +235,14 @@ func (ppu *PPU) BusRead(addr uint16, readOnly bool) uint8 {
 	case 0x0001: // Mask
 		break
 	case 0x0002: // Status
-		ppu.status.VerticalBlank = 1
-		ppu.status.Update()
+		if readOnly {
+			data = ppu.status.Reg
+			break
+		}
+		if ppu.scanLine == ppu.region.VBlankLine() && ppu.cycle == 1 {
+			// Reading one dot before vblank starts hides the flag and the NMI
+			ppu.suppressVBlank = true
+		}
 		data = (ppu.status.Reg & 0xE0) | (ppu.dataBuffer & 0x1F)
 		ppu.status.VerticalBlank = 0
 		ppu.status.Update()
@@ -246,17 +250,22 @@ func (ppu *PPU) BusRead(addr uint16, readOnly bool) uint8 {
 	case 0x0003: // OAM Address
 		break
 	case 0x0004: // OAM Data
-		break
+		data = ppu.oam[ppu.oamAddr]
 	case 0x0005: // Scroll
 		break
 	case 0x0006: // PPU Address
 		break
 	case 0x0007: // PPU Data
+		if readOnly {
+			data = ppu.dataBuffer
+			break
+		}
 		data = ppu.dataBuffer
-		ppu.dataBuffer = ppu.Read(ppu.address, false)
-		if ppu.address > 0x3F00 {
+		ppu.dataBuffer = ppu.Read(ppu.vramAddr.Reg, false)
+		if ppu.vramAddr.Reg >= 0x3F00 {
 			data = ppu.dataBuffer
 		}
+		ppu.incrementAddress()
 	default:
 		break
 	}
@@ -267,49 +276,79 @@ func (ppu *PPU) BusRead(addr uint16, readOnly bool) uint8 {
 func (ppu *PPU) BusWrite(addr uint16, data uint8) {
 	switch addr {
 	case 0x0000: // Control
-		break
+		ppu.control.Set(data)
+		ppu.tramAddr.NameTableX = ppu.control.NameTableX
+		ppu.tramAddr.NameTableY = ppu.control.NameTableY
+		ppu.tramAddr.Update()
 	case 0x0001: // Mask
-		break
+		ppu.mask.Set(data)
 	case 0x0002: // Status
 		break
 	case 0x0003: // OAM Address
-		break
+		ppu.oamAddr = data
 	case 0x0004: // OAM Data
-		break
+		ppu.WriteOAM(data)
 	case 0x0005: // Scroll
-		break
+		if ppu.addressLatch == 0 {
+			ppu.fineX = data & 0x07
+			ppu.tramAddr.CoarseX = data >> 3
+			ppu.tramAddr.Update()
+			ppu.addressLatch = 1
+		} else {
+			ppu.tramAddr.FineY = data & 0x07
+			ppu.tramAddr.CoarseY = data >> 3
+			ppu.tramAddr.Update()
+			ppu.addressLatch = 0
+		}
 	case 0x0006: // PPU Address
 		if ppu.addressLatch == 0 {
-			ppu.address = (ppu.address & 0xFF00) | uint16(data)
+			ppu.tramAddr.Set((uint16(data&0x3F) << 8) | (ppu.tramAddr.Reg & 0x00FF))
 			ppu.addressLatch = 1
 		} else {
-			ppu.address = (ppu.address & 0x00FF) | uint16(data)<<8
+			ppu.tramAddr.Set((ppu.tramAddr.Reg & 0xFF00) | uint16(data))
+			ppu.vramAddr = ppu.tramAddr
 			ppu.addressLatch = 0
+			// The new address is driven onto the bus, mappers watching A12 see it
+			ppu.rom.PPUAddressSeen(ppu.vramAddr.Reg & 0x3FFF)
 		}
 	case 0x0007: // PPU Data
-		ppu.Write(addr, data)
+		ppu.Write(ppu.vramAddr.Reg, data)
+		ppu.incrementAddress()
 	default:
 		break
 	}
 }
 
-func (ppu *PPU) ConnectCartridge(rom *cartridge.ROM) {
-	ppu.rom = rom
+func (ppu *PPU) WriteOAM(data uint8) {
+	if ppu.oamAddr&0x03 == 0x02 {
+		// Attribute bits 2-4 are unimplemented and read back as 0
+		data &= 0xE3
+	}
+	ppu.oam[ppu.oamAddr] = data
+	ppu.oamAddr++
+}
+
+func (ppu *PPU) incrementAddress() {
+	if ppu.control.IncrementMode != 0 {
+		ppu.vramAddr.Set(ppu.vramAddr.Reg + 32)
+	} else {
+		ppu.vramAddr.Set(ppu.vramAddr.Reg + 1)
+	}
 }
 
-func (ppu *PPU) ConnectRenderer(renderer *sdl.Renderer) {
-	ppu.renderer = renderer
+func (ppu *PPU) ConnectCartridge(rom *cartridge.ROM) {
+	ppu.rom = rom
 }
 
-func (ppu *PPU) GetScreen() *sdl.Surface {
+func (ppu *PPU) GetScreen() *image.RGBA {
 	return ppu.sprScreen
 }
 
-func (ppu *PPU) GetNameTable(i uint8) *sdl.Surface {
+func (ppu *PPU) GetNameTable(i uint8) *image.RGBA {
 	return ppu.sprNameTable[i]
 }
 
-func (ppu *PPU) GetPatternTable(i uint8, palette uint8) *sdl.Surface {
+func (ppu *PPU) GetPatternTable(i uint8, palette uint8) *image.RGBA {
 	for x := range uint16(16) {
 		for y := range uint16(16) {
 			offset := y*256 + x*16
@@ -317,12 +356,10 @@ func (ppu *PPU) GetPatternTable(i uint8, palette uint8) *sdl.Surface {
 				tileLSB := ppu.Read(uint16(i)*0x1000+offset+row, true)
 				tileMSB := ppu.Read(uint16(i)*0x1000+offset+row+8, true)
 				for col := range uint16(8) {
-					pixel := (tileLSB & 0x01) + (tileMSB & 0x01)
+					pixel := ((tileMSB & 0x01) << 1) | (tileLSB & 0x01)
 					tileLSB >>= 1
 					tileMSB >>= 1
-					color := ppu.GetColorPallette(palette, pixel)
-					argb := sdl.ARGB8888{A: color.R, R: color.G, G: color.B, B: color.A}
-					ppu.sprPatternTable[i].Set(int(x*8+(7-col)), int(y*8+row), argb)
+					ppu.sprPatternTable[i].SetRGBA(int(x*8+(7-col)), int(y*8+row), ppu.GetColorPallette(palette, pixel))
 				}
 			}
 		}
@@ -330,37 +367,164 @@ func (ppu *PPU) GetPatternTable(i uint8, palette uint8) *sdl.Surface {
 	return ppu.sprPatternTable[i]
 }
 
-func (ppu *PPU) GetColorPallette(palette uint8, pixel uint8) sdl.Color {
+func (ppu *PPU) GetColorPallette(palette uint8, pixel uint8) color.RGBA {
 	return ppu.palScreen[ppu.Read(0x3F00+uint16((palette<<2)+pixel), true)]
 }
 
 func (ppu *PPU) Clock() {
-	i := 0
-	if rand.Int()%2 != 0 {
-		i = 0x3F
-	} else {
-		i = 0x30
+	if ppu.scanLine >= -1 && ppu.scanLine < 240 {
+		if ppu.scanLine == 0 && ppu.cycle == 0 && ppu.oddFrame && ppu.renderingEnabled() && ppu.region == timing.NTSC {
+			// Odd frames skip the first idle dot when rendering, NTSC only
+			ppu.cycle = 1
+		}
+		// The PPU bus stays idle while rendering is off, so cartridges
+		// watching the fetches see nothing either
+		rendering := ppu.renderingEnabled()
+		if rendering && ((ppu.cycle >= 2 && ppu.cycle < 258) || (ppu.cycle >= 321 && ppu.cycle < 338)) {
+			ppu.updateShifters()
+			switch (ppu.cycle - 1) % 8 {
+			case 0:
+				ppu.loadBackgroundShifters()
+				ppu.fetchNameTable()
+			case 2:
+				ppu.fetchAttribute()
+			case 4:
+				ppu.fetchPatternLow()
+			case 6:
+				ppu.fetchPatternHigh()
+			case 7:
+				ppu.incrementScrollX()
+			}
+		}
+		if rendering && ppu.cycle == 256 {
+			ppu.incrementScrollY()
+		}
+		if rendering && ppu.cycle == 257 {
+			ppu.loadBackgroundShifters()
+			ppu.transferAddressX()
+		}
+		if rendering && (ppu.cycle == 338 || ppu.cycle == 340) {
+			ppu.fetchNameTable()
+		}
+		if ppu.scanLine == -1 && ppu.cycle >= 280 && ppu.cycle < 305 {
+			ppu.transferAddressY()
+		}
+		if ppu.scanLine == -1 && ppu.cycle == 1 {
+			ppu.status.VerticalBlank = 0
+			ppu.status.SpriteOverflow = 0
+			ppu.status.SpriteZeroHit = 0
+			ppu.status.Update()
+		}
+		if rendering && ppu.cycle == 257 {
+			ppu.evaluateSprites()
+		}
+		if rendering && ppu.cycle >= 257 && ppu.cycle <= 320 {
+			ppu.oamAddr = 0x00
+			slot := int(ppu.cycle-257) / 8
+			switch (ppu.cycle - 257) % 8 {
+			case 4:
+				ppu.fetchSprite(slot, false)
+			case 6:
+				ppu.fetchSprite(slot, true)
+			}
+		}
+	}
+
+	if ppu.scanLine == ppu.region.VBlankLine() && ppu.cycle == 1 {
+		if !ppu.suppressVBlank {
+			ppu.status.VerticalBlank = 1
+			ppu.status.Update()
+		}
+		ppu.suppressVBlank = false
+	}
+
+	if ppu.scanLine >= 0 && ppu.scanLine < ResY && ppu.cycle >= 1 && ppu.cycle <= ResX {
+		ppu.renderPixel()
 	}
-	c := ppu.palScreen[i]
-	ppu.renderer.SetDrawColor(c.R, c.G, c.B, c.A)
-	ppu.renderer.DrawPoint(int32(ppu.cycle)-1, int32(ppu.scanLine))
 
 	ppu.cycle++
 	if ppu.cycle >= 341 {
 		ppu.cycle = 0
 		ppu.scanLine++
-		if ppu.scanLine >= 261 {
-			ppu.scanLine = 0xFFFF
+		if ppu.scanLine >= ppu.region.ScanLines()-1 {
+			ppu.scanLine = -1
 			ppu.frameComplete = true
+			ppu.oddFrame = !ppu.oddFrame
 		}
 	}
+}
 
+func (ppu *PPU) renderPixel() {
+	var bgPixel, bgPalette uint8 = 0x00, 0x00
+	if ppu.mask.RenderBackground != 0 && (ppu.mask.RenderBackgroundLeft != 0 || ppu.cycle >= 9) {
+		bgPixel, bgPalette = ppu.backgroundPixel()
+	}
+	var fgPixel, fgPalette uint8 = 0x00, 0x00
+	var fgFront, fgZero bool = false, false
+	if ppu.mask.RenderSprite != 0 && (ppu.mask.RenderSpritesLeft != 0 || ppu.cycle >= 9) {
+		fgPixel, fgPalette, fgFront, fgZero = ppu.spritePixel(int(ppu.cycle) - 1)
+	}
+
+	var pixel, palette uint8 = 0x00, 0x00
+	switch {
+	case bgPixel == 0 && fgPixel == 0:
+		break
+	case bgPixel == 0:
+		pixel, palette = fgPixel, fgPalette
+	case fgPixel == 0:
+		pixel, palette = bgPixel, bgPalette
+	default:
+		if fgFront {
+			pixel, palette = fgPixel, fgPalette
+		} else {
+			pixel, palette = bgPixel, bgPalette
+		}
+		if fgZero && ppu.cycle != 256 {
+			ppu.status.SpriteZeroHit = 1
+			ppu.status.Update()
+		}
+	}
+	ppu.sprScreen.SetRGBA(int(ppu.cycle)-1, int(ppu.scanLine), ppu.GetColorPallette(palette, pixel))
+}
+
+func (ppu *PPU) NMILine() bool {
+	return ppu.status.VerticalBlank != 0 && ppu.control.EnableNMI != 0
+}
+
+func (ppu *PPU) renderingEnabled() bool {
+	return ppu.mask.RenderBackground != 0 || ppu.mask.RenderSprite != 0
 }
 
 func (ppu *PPU) Reset() {
 	ppu.frameComplete = false
+	ppu.oddFrame = false
+	ppu.scanLine = 0
+	ppu.cycle = 0
+	ppu.status.Set(0x00)
+	ppu.mask.Set(0x00)
+	ppu.control.Set(0x00)
+	ppu.vramAddr.Set(0x0000)
+	ppu.tramAddr.Set(0x0000)
+	ppu.fineX = 0x00
+	ppu.addressLatch = 0
+	ppu.dataBuffer = 0x00
+	ppu.bg = background{}
+	ppu.oamAddr = 0x00
+	ppu.secondaryOAM = [32]uint8{}
+	ppu.sprites = [8]sprite{}
+	ppu.spriteCount = 0
+	ppu.spriteZero = false
+	ppu.suppressVBlank = false
+}
+
+func (ppu *PPU) SetRegion(region timing.Region) {
+	ppu.region = region
 }
 
 func (ppu *PPU) FrameComplete() bool {
 	return ppu.frameComplete
 }
+
+func (ppu *PPU) ClearFrameComplete() {
+	ppu.frameComplete = false
+}
@@ -43,7 +43,15 @@ func MakeStatusRegister() StatusRegister {
 }
 
 func (r *StatusRegister) Update() {
-	r.Reg = (r.Unused << 4) | (r.SpriteOverflow << 5) | (r.SpriteZeroHit << 6) | (r.VerticalBlank << 7)
+	r.Reg = (r.Unused << 0) | (r.SpriteOverflow << 5) | (r.SpriteZeroHit << 6) | (r.VerticalBlank << 7)
+}
+
+func (r *StatusRegister) Set(data uint8) {
+	r.Reg = data
+	r.Unused = data & 0x1F
+	r.SpriteOverflow = (data >> 5) & 0x01
+	r.SpriteZeroHit = (data >> 6) & 0x01
+	r.VerticalBlank = (data >> 7) & 0x01
 }
 
 func MakeMaskRegister() MaskRegister {
@@ -64,6 +72,18 @@ func (r *MaskRegister) Update() {
 	r.Reg = (r.Grayscale << 0) | (r.RenderBackgroundLeft << 1) | (r.RenderSpritesLeft << 2) | (r.RenderBackground << 3) | (r.RenderSprite << 4) | (r.EnchanceRed << 5) | (r.EnchanceGreen << 6) | (r.EnchanceBlue << 7)
 }
 
+func (r *MaskRegister) Set(data uint8) {
+	r.Reg = data
+	r.Grayscale = (data >> 0) & 0x01
+	r.RenderBackgroundLeft = (data >> 1) & 0x01
+	r.RenderSpritesLeft = (data >> 2) & 0x01
+	r.RenderBackground = (data >> 3) & 0x01
+	r.RenderSprite = (data >> 4) & 0x01
+	r.EnchanceRed = (data >> 5) & 0x01
+	r.EnchanceGreen = (data >> 6) & 0x01
+	r.EnchanceBlue = (data >> 7) & 0x01
+}
+
 func MakeControlRegister() ControlRegister {
 	return ControlRegister{
 		NameTableX:        1,
@@ -81,3 +101,52 @@ func MakeControlRegister() ControlRegister {
 func (r *ControlRegister) Update() {
 	r.Reg = (r.NameTableX << 0) | (r.NameTableY << 1) | (r.IncrementMode << 2) | (r.PatternSprite << 3) | (r.PatternBackground << 4) | (r.SpriteSize << 5) | (r.SlaveMode << 6) | (r.EnableNMI << 7)
 }
+
+func (r *ControlRegister) Set(data uint8) {
+	r.Reg = data
+	r.NameTableX = (data >> 0) & 0x01
+	r.NameTableY = (data >> 1) & 0x01
+	r.IncrementMode = (data >> 2) & 0x01
+	r.PatternSprite = (data >> 3) & 0x01
+	r.PatternBackground = (data >> 4) & 0x01
+	r.SpriteSize = (data >> 5) & 0x01
+	r.SlaveMode = (data >> 6) & 0x01
+	r.EnableNMI = (data >> 7) & 0x01
+}
+
+// Internal VRAM address, see https://www.nesdev.org/wiki/PPU_scrolling
+type LoopyRegister struct {
+	CoarseX    uint8
+	CoarseY    uint8
+	NameTableX uint8
+	NameTableY uint8
+	FineY      uint8
+	Unused     uint8
+	Reg        uint16 // Read only
+}
+
+func MakeLoopyRegister() LoopyRegister {
+	return LoopyRegister{
+		CoarseX:    0,
+		CoarseY:    0,
+		NameTableX: 0,
+		NameTableY: 0,
+		FineY:      0,
+		Unused:     0,
+		Reg:        0x0000,
+	}
+}
+
+func (r *LoopyRegister) Update() {
+	r.Reg = (uint16(r.CoarseX) << 0) | (uint16(r.CoarseY) << 5) | (uint16(r.NameTableX) << 10) | (uint16(r.NameTableY) << 11) | (uint16(r.FineY) << 12) | (uint16(r.Unused) << 15)
+}
+
+func (r *LoopyRegister) Set(data uint16) {
+	r.Reg = data
+	r.CoarseX = uint8(data>>0) & 0x1F
+	r.CoarseY = uint8(data>>5) & 0x1F
+	r.NameTableX = uint8(data>>10) & 0x01
+	r.NameTableY = uint8(data>>11) & 0x01
+	r.FineY = uint8(data>>12) & 0x07
+	r.Unused = uint8(data>>15) & 0x01
+}
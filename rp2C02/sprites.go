@@ -0,0 +1,139 @@
+package rp2C02
+
+type sprite struct {
+	y      uint8
+	id     uint8
+	attrib uint8
+	x      uint8
+	lsb    uint8
+	msb    uint8
+	zero   bool
+}
+
+const (
+	spriteFlipVertical   uint8 = (1 << 7)
+	spriteFlipHorizontal uint8 = (1 << 6)
+	spritePriority       uint8 = (1 << 5)
+)
+
+func (ppu *PPU) spriteHeight() int16 {
+	if ppu.control.SpriteSize != 0 {
+		return 16
+	}
+	return 8
+}
+
+func (ppu *PPU) spriteInRange(y uint8) bool {
+	diff := ppu.scanLine - int16(y)
+	return diff >= 0 && diff < ppu.spriteHeight()
+}
+
+// Fills secondary OAM with the sprites of the next scanline, including the
+// diagonal OAM walk that makes the overflow flag unreliable on hardware
+func (ppu *PPU) evaluateSprites() {
+	for i := range ppu.secondaryOAM {
+		ppu.secondaryOAM[i] = 0xFF
+	}
+	ppu.spriteCount = 0
+	ppu.spriteZero = false
+	n := 0
+	for n < 64 && ppu.spriteCount < 8 {
+		y := ppu.oam[n*4]
+		if ppu.spriteInRange(y) {
+			copy(ppu.secondaryOAM[ppu.spriteCount*4:ppu.spriteCount*4+4], ppu.oam[n*4:n*4+4])
+			if n == 0 {
+				ppu.spriteZero = true
+			}
+			ppu.spriteCount++
+		}
+		n++
+	}
+	m := 0
+	for n < 64 {
+		if ppu.spriteInRange(ppu.oam[n*4+m]) {
+			ppu.status.SpriteOverflow = 1
+			ppu.status.Update()
+			break
+		}
+		n++
+		m = (m + 1) & 0x03
+	}
+}
+
+func (ppu *PPU) spritePatternAddress(s *sprite) uint16 {
+	row := uint16(ppu.scanLine - int16(s.y))
+	if ppu.control.SpriteSize == 0 {
+		if s.attrib&spriteFlipVertical != 0 {
+			row = 7 - row
+		}
+		return (uint16(ppu.control.PatternSprite) << 12) | (uint16(s.id) << 4) | row
+	}
+	if s.attrib&spriteFlipVertical != 0 {
+		row = 15 - row
+	}
+	tile := uint16(s.id & 0xFE)
+	if row >= 8 {
+		tile++
+		row -= 8
+	}
+	return (uint16(s.id&0x01) << 12) | (tile << 4) | row
+}
+
+// Fetches the pattern of one secondary OAM slot during dots 257-320, empty
+// slots still fetch tile $FF so the cartridge sees the same address traffic
+func (ppu *PPU) fetchSprite(slot int, high bool) {
+	s := &ppu.sprites[slot]
+	if !high {
+		s.y = ppu.secondaryOAM[slot*4+0]
+		s.id = ppu.secondaryOAM[slot*4+1]
+		s.attrib = ppu.secondaryOAM[slot*4+2]
+		s.x = ppu.secondaryOAM[slot*4+3]
+		s.zero = slot == 0 && ppu.spriteZero
+	}
+	var addr uint16
+	if slot < int(ppu.spriteCount) {
+		addr = ppu.spritePatternAddress(s)
+	} else {
+		addr = (uint16(ppu.control.PatternSprite) << 12) | 0x0FF0
+		if ppu.control.SpriteSize != 0 {
+			addr = 0x1FF0
+		}
+	}
+	if high {
+		addr += 8
+	}
+	data := ppu.Read(addr, false)
+	if slot >= int(ppu.spriteCount) {
+		data = 0x00
+	} else if s.attrib&spriteFlipHorizontal != 0 {
+		data = flipByte(data)
+	}
+	if high {
+		s.msb = data
+	} else {
+		s.lsb = data
+	}
+}
+
+func (ppu *PPU) spritePixel(x int) (uint8, uint8, bool, bool) {
+	for i := range int(ppu.spriteCount) {
+		s := &ppu.sprites[i]
+		offset := x - int(s.x)
+		if offset < 0 || offset >= 8 {
+			continue
+		}
+		bit := uint(7 - offset)
+		pixel := ((s.msb>>bit)&0x01)<<1 | (s.lsb>>bit)&0x01
+		if pixel != 0 {
+			return pixel, (s.attrib & 0x03) + 0x04, s.attrib&spritePriority == 0, s.zero
+		}
+	}
+	return 0x00, 0x00, false, false
+}
+
+func flipByte(b uint8) uint8 {
+	b = (b&0xF0)>>4 | (b&0x0F)<<4
+	b = (b&0xCC)>>2 | (b&0x33)<<2
+	b = (b&0xAA)>>1 | (b&0x55)<<1
+	return b
+}
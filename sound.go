@@ -0,0 +1,85 @@
+package main
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/laranc/emuNES/audio"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	sampleRate    = 44100
+	deviceSamples = 512
+	ringSamples   = 4096 // ~93 ms, rate control keeps it half full
+)
+
+type sdlSink struct {
+	device sdl.AudioDeviceID
+	ring   *audio.Ring
+	rate   int
+	done   chan struct{}
+}
+
+func newSDLSink() (*sdlSink, error) {
+	desired := sdl.AudioSpec{
+		Freq:     sampleRate,
+		Format:   sdl.AUDIO_F32SYS,
+		Channels: 1,
+		Samples:  deviceSamples,
+	}
+	obtained := sdl.AudioSpec{}
+	device, err := sdl.OpenAudioDevice("", false, &desired, &obtained, 0)
+	if err != nil {
+		return nil, err
+	}
+	s := &sdlSink{
+		device: device,
+		ring:   audio.NewRing(ringSamples),
+		rate:   int(obtained.Freq),
+		done:   make(chan struct{}),
+	}
+	go s.feed()
+	sdl.PauseAudioDevice(device, false)
+	return s, nil
+}
+
+// Keeps SDL's own queue short and the latency in the ring, where the rate
+// control can see it
+func (s *sdlSink) feed() {
+	buf := make([]float32, deviceSamples)
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		if sdl.GetQueuedAudioSize(s.device) < deviceSamples*2*4 {
+			n := s.ring.Read(buf)
+			if n > 0 {
+				sdl.QueueAudio(s.device, unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), n*4))
+				continue
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (s *sdlSink) Write(samples []float32) {
+	s.ring.Write(samples)
+}
+
+func (s *sdlSink) Fill() float64 {
+	return float64(s.ring.Len()) / float64(s.ring.Cap())
+}
+
+func (s *sdlSink) SampleRate() int {
+	return s.rate
+}
+
+func (s *sdlSink) Close() {
+	close(s.done)
+	// Unblock a writer waiting on a full ring
+	s.ring.Read(make([]float32, s.ring.Cap()))
+	sdl.CloseAudioDevice(s.device)
+}
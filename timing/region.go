@@ -0,0 +1,73 @@
+package timing
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Region uint8
+
+const (
+	NTSC Region = iota
+	PAL
+	Dendy
+)
+
+func ParseRegion(s string) (Region, error) {
+	switch strings.ToLower(s) {
+	case "ntsc":
+		return NTSC, nil
+	case "pal":
+		return PAL, nil
+	case "dendy":
+		return Dendy, nil
+	default:
+		return NTSC, fmt.Errorf("unknown region %q", s)
+	}
+}
+
+func (r Region) String() string {
+	switch r {
+	case PAL:
+		return "pal"
+	case Dendy:
+		return "dendy"
+	default:
+		return "ntsc"
+	}
+}
+
+// CPU clock in Hz
+func (r Region) CPUFrequency() float64 {
+	switch r {
+	case PAL:
+		return 1662607
+	case Dendy:
+		return 1773448
+	default:
+		return 1789773
+	}
+}
+
+// PPU dots per CPU cycle as a fraction, 3.2 on PAL
+func (r Region) PPUDivider() (int, int) {
+	if r == PAL {
+		return 16, 5
+	}
+	return 3, 1
+}
+
+// Scanlines per frame including the pre-render line
+func (r Region) ScanLines() int16 {
+	if r == NTSC {
+		return 262
+	}
+	return 312
+}
+
+func (r Region) VBlankLine() int16 {
+	if r == Dendy {
+		return 291
+	}
+	return 241
+}